@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/pkg/errors"
+	"github.com/spoke-d/clui/flagset"
 	"github.com/spoke-d/clui/group"
 )
 
@@ -13,26 +14,75 @@ import (
 // global flags.
 type GlobalArgs struct {
 	commands     *group.Group
+	globalFlags  *GlobalFlags
 	commandFlags []string
+	args         []string
 
-	subCommand      string
-	subCommandArgs  []string
-	subCommandFlags []string
+	subCommand        string
+	subCommandArgs    []string
+	subCommandFlags   []string
+	subCommandRawArgs []string
 
 	isHelp, isVersion, isDebug, isDevMode bool
 	requiresInstall, requiresUninstall    bool
 	requiresNoColor                       bool
 	requiresNoSubKeys                     bool
+	configPath                            string
+	outputFormat                          string
+}
+
+// GlobalArgsOptions represents a way to set optional values to a global args
+// option.
+// The GlobalArgsOptions shows what options are available to change.
+type GlobalArgsOptions interface {
+	SetGlobalFlags(*GlobalFlags)
+}
+
+// GlobalArgsOption captures a tweak that can be applied to the GlobalArgs.
+type GlobalArgsOption func(GlobalArgsOptions)
+
+type globalArgs struct {
+	globalFlags *GlobalFlags
+}
+
+func (s *globalArgs) SetGlobalFlags(p *GlobalFlags) {
+	s.globalFlags = p
+}
+
+func (s *globalArgs) GlobalFlags() *GlobalFlags {
+	if s.globalFlags == nil {
+		return NewGlobalFlags()
+	}
+	return s.globalFlags
+}
+
+// OptionGlobalFlags allows the setting a global flags option to configure the
+// global args.
+func OptionGlobalFlags(i *GlobalFlags) GlobalArgsOption {
+	return func(opt GlobalArgsOptions) {
+		opt.SetGlobalFlags(i)
+	}
 }
 
 // NewGlobalArgs creates a new GlobalArgs type for processing arguments passed
 // to the cli.
-func NewGlobalArgs(commands *group.Group) *GlobalArgs {
+func NewGlobalArgs(commands *group.Group, options ...GlobalArgsOption) *GlobalArgs {
+	opt := new(globalArgs)
+	for _, option := range options {
+		option(opt)
+	}
+
 	return &GlobalArgs{
-		commands: commands,
+		commands:    commands,
+		globalFlags: opt.GlobalFlags(),
 	}
 }
 
+// GlobalFlags returns the global flags that have been parsed.
+func (a *GlobalArgs) GlobalFlags() *flagset.FlagSet {
+	return a.globalFlags.FlagSet()
+}
+
 // SubCommand returns the sub command name.
 func (a *GlobalArgs) SubCommand() string {
 	return a.subCommand
@@ -48,6 +98,12 @@ func (a *GlobalArgs) SubCommandFlags() []string {
 	return a.subCommandFlags
 }
 
+// SubCommandRawArgs returns the sub command arguments and flags, in the order
+// they were passed.
+func (a *GlobalArgs) SubCommandRawArgs() []string {
+	return a.subCommandRawArgs
+}
+
 // CommandFlags returns the command arguments.
 func (a *GlobalArgs) CommandFlags() []string {
 	return a.commandFlags
@@ -73,6 +129,18 @@ func (a *GlobalArgs) DevMode() bool {
 	return a.isDevMode
 }
 
+// ConfigPath returns the path of the config file, if the operator has passed
+// the config flag.
+func (a *GlobalArgs) ConfigPath() string {
+	return a.configPath
+}
+
+// OutputFormat returns the output format the operator has passed with the
+// format flag.
+func (a *GlobalArgs) OutputFormat() string {
+	return a.outputFormat
+}
+
 // RequiresInstall returns if the operator has passed the requires install flag.
 func (a *GlobalArgs) RequiresInstall() bool {
 	return a.requiresInstall
@@ -97,41 +165,51 @@ func (a *GlobalArgs) RequiresNoSubKeys() bool {
 // Process consumes the arguments and correctly separates them between global
 // flags and arguments and command flags and arguments.
 func (a *GlobalArgs) Process(args []string) error {
-	var processed []string
-	// first remove the default
-	for _, arg := range args {
-		if arg == "--" {
-			break
-		}
+	a.args = args
+	return a.process(args, nil)
+}
 
-		switch arg {
-		case "-h", "-help", "--help":
-			a.isHelp = true
-		case "-v", "-version", "--version":
-			a.isVersion = true
-		case "--debug":
-			a.isDebug = true
-		case "--dev-mode":
-			a.isDevMode = true
-		case "--no-color":
-			a.requiresNoColor = true
-		case "--no-sub-keys":
-			a.requiresNoSubKeys = true
-		case "--autocomplete-install":
-			a.requiresInstall = true
-		case "--autocomplete-uninstall":
-			a.requiresUninstall = true
-		default:
-			processed = append(processed, arg)
-		}
+// Shadow processes the arguments again, leaving the shadowed global flags for
+// the sub command when they're passed after it. This allows a command to
+// define a flag with the same name as a global flag.
+func (a *GlobalArgs) Shadow(shadowed map[string]bool) error {
+	return a.process(a.args, shadowed)
+}
+
+func (a *GlobalArgs) process(args []string, shadowed map[string]bool) error {
+	a.commandFlags = nil
+	a.subCommand = ""
+	a.subCommandArgs = nil
+	a.subCommandFlags = nil
+	a.subCommandRawArgs = nil
+
+	// first remove the global flags
+	processed, err := a.globalFlags.parse(args, shadowed)
+	if err != nil {
+		return err
 	}
 
+	a.isHelp = a.globalFlags.bool(flagHelp)
+	a.isVersion = a.globalFlags.bool(flagVersion)
+	a.isDebug = a.globalFlags.bool(flagDebug)
+	a.isDevMode = a.globalFlags.bool(flagDevMode)
+	a.requiresNoColor = a.globalFlags.bool(flagNoColor)
+	a.requiresNoSubKeys = a.globalFlags.bool(flagNoSubKeys)
+	a.requiresInstall = a.globalFlags.bool(flagAutoCompleteInstall)
+	a.requiresUninstall = a.globalFlags.bool(flagAutoCompleteUninstall)
+	a.configPath = a.globalFlags.string(flagConfig)
+	a.outputFormat = a.globalFlags.string(flagFormat)
+
 	if a.requiresInstall && a.requiresUninstall {
 		return errors.Errorf("both autocomplete flags can not be used at the same time")
 	}
 
 	for i, arg := range processed {
 		if a.subCommand == "" {
+			// There is no sub command to pass the arguments to.
+			if arg == "--" {
+				break
+			}
 			if arg != "" && arg[0] == '-' {
 				// Record the arg...
 				a.commandFlags = append(a.commandFlags, arg)
@@ -163,8 +241,9 @@ func (a *GlobalArgs) Process(args []string) error {
 				}
 
 				// Nested CLI the subCommand is actually the entire arg list up
-				// to a flag that is still a valid subCommand.
-				searchKey := strings.Join(processed[i:j], " ")
+				// to a flag that is still a valid subCommand. Aliases of parent
+				// commands are resolved first, so their sub commands are found.
+				searchKey := a.commands.Resolve(strings.Join(processed[i:j], " "))
 				k, ok := a.commands.LongestPrefix(searchKey)
 				if ok {
 					// k could be a prefix that doesn't contain the full command
@@ -182,10 +261,14 @@ func (a *GlobalArgs) Process(args []string) error {
 				}
 			}
 
+			// Aliases are resolved to the command they represent.
+			a.subCommand = a.commands.Resolve(a.subCommand)
+
 			// The remaining processed the subCommand arguments
 
 			a.subCommandArgs = removeFlags(processed[i+1:])
 			a.subCommandFlags = removeNonFlags(processed[i+1:])
+			a.subCommandRawArgs = processed[i+1:]
 		}
 	}
 
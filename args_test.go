@@ -212,4 +212,38 @@ func TestGlobalArgs(t *testing.T) {
 			t.Errorf("expected: %v, actual: %v", expected, actual)
 		}
 	})
+
+	t.Run("command alias", func(t *testing.T) {
+		group := group.New()
+		group.Add("a b", nil, OptionAliases("c"))
+
+		args := NewGlobalArgs(group)
+		err := args.Process([]string{"a", "c", "d"})
+		if expected, actual := true, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v, err: %v", expected, actual, err)
+		}
+		if expected, actual := "a b", args.SubCommand(); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := []string{"d"}, args.SubCommandArgs(); !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+	t.Run("nested command under alias", func(t *testing.T) {
+		group := group.New()
+		group.Add("config", nil, OptionAliases("cfg"))
+		group.Add("config show", nil)
+
+		args := NewGlobalArgs(group)
+		err := args.Process([]string{"cfg", "show", "name"})
+		if expected, actual := true, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v, err: %v", expected, actual, err)
+		}
+		if expected, actual := "config show", args.SubCommand(); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := []string{"name"}, args.SubCommandArgs(); !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
 }
@@ -0,0 +1,216 @@
+package argset
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spoke-d/clui/autocomplete/args"
+	"github.com/spoke-d/clui/commands"
+	"github.com/spoke-d/clui/flagset"
+)
+
+// Arg describes a positional argument of a command.
+type Arg struct {
+	// Name of the argument, which is used in the usage and errors.
+	Name string
+
+	// Usage is a short description of the argument.
+	Usage string
+
+	// Type validates the value of the argument. String is used if no type is
+	// given.
+	Type Type
+
+	// Optional arguments don't have to be passed. Only the last arguments can
+	// be optional.
+	Optional bool
+
+	// Variadic arguments consume all the remaining values. Only the last
+	// argument can be variadic.
+	Variadic bool
+
+	// Predictor predicts the value of the argument for autocomplete. If no
+	// predictor is given, then the type is used if it's a predictor.
+	Predictor args.Predictor
+}
+
+// usage returns the argument for the usage line, such as "<file>" or
+// "[<file>...]".
+func (a Arg) usage() string {
+	res := fmt.Sprintf("<%s>", a.Name)
+	if a.Variadic {
+		res += "..."
+	}
+	if a.Optional {
+		res = fmt.Sprintf("[%s]", res)
+	}
+	return res
+}
+
+func (a Arg) predictor() args.Predictor {
+	if a.Predictor != nil {
+		return a.Predictor
+	}
+	if p, ok := a.Type.(args.Predictor); ok {
+		return p
+	}
+	return nil
+}
+
+// ArgSet is a specification of the positional arguments of a command. The
+// arguments are checked before the command is initialized.
+type ArgSet struct {
+	args   []Arg
+	values map[string][]string
+}
+
+// New creates an ArgSet from the arguments, in the order they're passed.
+// Returns an error if a required argument follows an optional argument, or if
+// a variadic argument isn't the last argument, as that can't be parsed.
+func New(arguments ...Arg) (*ArgSet, error) {
+	arguments = append([]Arg(nil), arguments...)
+	for i, arg := range arguments {
+		if arg.Type == nil {
+			arguments[i].Type = String
+		}
+		if i == 0 {
+			continue
+		}
+		prev := arguments[i-1]
+		if prev.Optional && !arg.Optional {
+			return nil, errors.Errorf("required argument %q follows optional argument %q", arg.Name, prev.Name)
+		}
+		if prev.Variadic {
+			return nil, errors.Errorf("argument %q follows variadic argument %q", arg.Name, prev.Name)
+		}
+	}
+	return &ArgSet{
+		args:   arguments,
+		values: make(map[string][]string),
+	}, nil
+}
+
+// Args returns the specification of the arguments.
+func (s *ArgSet) Args() []Arg {
+	return s.args
+}
+
+// Usage returns the usage line of the arguments, such as
+// "<src> [<dst>...]".
+func (s *ArgSet) Usage() string {
+	usages := make([]string, len(s.args))
+	for i, arg := range s.args {
+		usages[i] = arg.usage()
+	}
+	return strings.Join(usages, " ")
+}
+
+// Parse checks the values against the specification of the arguments, so
+// that they can be accessed by name.
+// Returns a usage error naming the argument, if an argument is missing, there
+// are too many arguments or a value is invalid for the type of the argument.
+func (s *ArgSet) Parse(values []string) error {
+	s.values = make(map[string][]string)
+
+	var i int
+	for _, arg := range s.args {
+		if i >= len(values) {
+			if !arg.Optional {
+				return commands.Usagef("missing argument %s", arg.usage())
+			}
+			continue
+		}
+
+		n := 1
+		if arg.Variadic {
+			n = len(values) - i
+		}
+		for _, value := range values[i : i+n] {
+			if err := arg.Type.Validate(value); err != nil {
+				return commands.Usagef("invalid value %q for argument <%s>: %v", value, arg.Name, err)
+			}
+		}
+		s.values[arg.Name] = values[i : i+n]
+		i += n
+	}
+
+	if i < len(values) {
+		return commands.Usagef("too many arguments: %s", strings.Join(values[i:], " "))
+	}
+	return nil
+}
+
+// Complete predicts the value of the argument that's being typed, based on
+// the position of it. The flags of the command are used to skip the values of
+// the flags that take one.
+func (s *ArgSet) Complete(a *args.Args, flags *flagset.FlagSet) []string {
+	if len(s.args) == 0 {
+		return nil
+	}
+	pos := len(a.CompletedCommands())
+	if flags != nil {
+		_, values := flags.Split(a.Completed())
+		pos = len(values)
+	}
+	if pos >= len(s.args) {
+		if last := s.args[len(s.args)-1]; last.Variadic {
+			pos = len(s.args) - 1
+		} else {
+			return nil
+		}
+	}
+	if predictor := s.args[pos].predictor(); predictor != nil {
+		return predictor.Predict(a)
+	}
+	return nil
+}
+
+// Passed returns if a value was passed for the named argument.
+func (s *ArgSet) Passed(name string) bool {
+	_, ok := s.values[name]
+	return ok
+}
+
+// String returns the value of the named argument, or an empty string if it
+// wasn't passed.
+func (s *ArgSet) String(name string) string {
+	if values := s.values[name]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// Strings returns all the values of the named variadic argument.
+func (s *ArgSet) Strings(name string) []string {
+	return s.values[name]
+}
+
+// Int returns the value of the named Int argument, or 0 if it wasn't passed.
+func (s *ArgSet) Int(name string) int {
+	v, _ := strconv.Atoi(s.String(name))
+	return v
+}
+
+// Float64 returns the value of the named Float64 argument, or 0 if it wasn't
+// passed.
+func (s *ArgSet) Float64(name string) float64 {
+	v, _ := strconv.ParseFloat(s.String(name), 64)
+	return v
+}
+
+// Bool returns the value of the named Bool argument, or false if it wasn't
+// passed.
+func (s *ArgSet) Bool(name string) bool {
+	v, _ := strconv.ParseBool(s.String(name))
+	return v
+}
+
+// Duration returns the value of the named Duration argument, or 0 if it
+// wasn't passed.
+func (s *ArgSet) Duration(name string) time.Duration {
+	v, _ := time.ParseDuration(s.String(name))
+	return v
+}
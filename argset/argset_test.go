@@ -0,0 +1,158 @@
+package argset
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/spoke-d/clui/autocomplete/args"
+	"github.com/spoke-d/clui/commands"
+	"github.com/spoke-d/clui/flagset"
+)
+
+func TestUsage(t *testing.T) {
+	for _, testcase := range []struct {
+		args []Arg
+		want string
+	}{
+		{[]Arg{}, ""},
+		{[]Arg{{Name: "src"}, {Name: "dst"}}, "<src> <dst>"},
+		{[]Arg{{Name: "src"}, {Name: "dst", Optional: true}}, "<src> [<dst>]"},
+		{[]Arg{{Name: "files", Variadic: true}}, "<files>..."},
+		{[]Arg{{Name: "src"}, {Name: "files", Optional: true, Variadic: true}}, "<src> [<files>...]"},
+	} {
+		t.Run(testcase.want, func(t *testing.T) {
+			if expected, actual := testcase.want, newTestArgSet(t, testcase.args...).Usage(); expected != actual {
+				t.Errorf("expected: %v, actual: %v", expected, actual)
+			}
+		})
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	for _, testcase := range []struct {
+		name string
+		args []Arg
+		want string
+	}{
+		{"required after optional", []Arg{{Name: "a", Optional: true}, {Name: "b"}}, `required argument "b" follows optional argument "a"`},
+		{"after variadic", []Arg{{Name: "a", Variadic: true}, {Name: "b"}}, `argument "b" follows variadic argument "a"`},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			_, err := New(testcase.args...)
+			if expected, actual := testcase.want, fmt.Sprint(err); expected != actual {
+				t.Errorf("expected: %v, actual: %v", expected, actual)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	newArgSet := func() *ArgSet {
+		return newTestArgSet(t,
+			Arg{Name: "name"},
+			Arg{Name: "count", Type: Int},
+			Arg{Name: "timeout", Type: Duration, Optional: true},
+			Arg{Name: "tags", Optional: true, Variadic: true},
+		)
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		argSet := newArgSet()
+		if err := argSet.Parse([]string{"fred", "3", "1m", "a", "b"}); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := "fred", argSet.String("name"); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := 3, argSet.Int("count"); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := time.Minute, argSet.Duration("timeout"); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := []string{"a", "b"}, argSet.Strings("tags"); !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("optional", func(t *testing.T) {
+		argSet := newArgSet()
+		if err := argSet.Parse([]string{"fred", "3"}); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := false, argSet.Passed("timeout"); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := 0, len(argSet.Strings("tags")); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	for _, testcase := range []struct {
+		name   string
+		values []string
+		want   string
+	}{
+		{"missing", []string{"fred"}, "missing argument <count>"},
+		{"invalid", []string{"fred", "three"}, `invalid value "three" for argument <count>: expected an integer: invalid syntax`},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			err := newArgSet().Parse(testcase.values)
+			if expected, actual := testcase.want, fmt.Sprint(err); expected != actual {
+				t.Errorf("expected: %v, actual: %v", expected, actual)
+			}
+			if expected, actual := true, commands.IsUsageError(err); expected != actual {
+				t.Errorf("expected: %v, actual: %v", expected, actual)
+			}
+		})
+	}
+
+	t.Run("too many", func(t *testing.T) {
+		err := newTestArgSet(t, Arg{Name: "name"}).Parse([]string{"fred", "bob", "alice"})
+		if expected, actual := "too many arguments: bob alice", fmt.Sprint(err); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+}
+
+func TestComplete(t *testing.T) {
+	argSet := newTestArgSet(t,
+		Arg{Name: "mode", Type: Choice("merge", "replace")},
+		Arg{Name: "name"},
+		Arg{Name: "tags", Variadic: true, Predictor: args.PredictSet("a", "b")},
+	)
+	flags := flagset.New("cli", flag.ContinueOnError)
+	flags.String("mode", "", "")
+	flags.BoolVarP(new(bool), "recursive", "r", false, "")
+
+	for _, testcase := range []struct {
+		line string
+		want []string
+	}{
+		{"cli ", []string{"merge", "replace"}},
+		{"cli merge ", nil},
+		{"cli merge fred ", []string{"a", "b"}},
+		{"cli merge fred a b ", []string{"a", "b"}},
+		{"cli --mode fast ", []string{"merge", "replace"}},
+		{"cli -r merge --mode fast ", nil},
+		{"cli merge --mode=fast fred ", []string{"a", "b"}},
+		{"cli -- --mode ", nil},
+	} {
+		t.Run(testcase.line, func(t *testing.T) {
+			if expected, actual := testcase.want, argSet.Complete(args.New(testcase.line), flags); !reflect.DeepEqual(expected, actual) {
+				t.Errorf("expected: %v, actual: %v", expected, actual)
+			}
+		})
+	}
+}
+
+func newTestArgSet(t *testing.T, arguments ...Arg) *ArgSet {
+	argSet, err := New(arguments...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return argSet
+}
@@ -0,0 +1,101 @@
+package argset
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spoke-d/clui/autocomplete/args"
+)
+
+// Type validates the value of a positional argument.
+type Type interface {
+
+	// Validate returns an error if the value isn't valid for the type.
+	Validate(string) error
+}
+
+var (
+	// String accepts any value.
+	String Type = typeFunc(func(string) error {
+		return nil
+	})
+
+	// Int accepts integers.
+	Int Type = typeFunc(func(v string) error {
+		_, err := strconv.Atoi(v)
+		return errors.Wrap(unwrapNum(err), "expected an integer")
+	})
+
+	// Float64 accepts floating point numbers.
+	Float64 Type = typeFunc(func(v string) error {
+		_, err := strconv.ParseFloat(v, 64)
+		return errors.Wrap(unwrapNum(err), "expected a number")
+	})
+
+	// Bool accepts the values accepted by strconv.ParseBool.
+	Bool Type = typeFunc(func(v string) error {
+		_, err := strconv.ParseBool(v)
+		return errors.Wrap(unwrapNum(err), "expected a boolean")
+	})
+
+	// Duration accepts the values accepted by time.ParseDuration.
+	Duration Type = typeFunc(func(v string) error {
+		_, err := time.ParseDuration(v)
+		return errors.Wrap(err, "expected a duration")
+	})
+)
+
+// File accepts any value, and predicts the files that match the pattern, such
+// as "*.yaml".
+func File(pattern string) Type {
+	return predictedType{
+		Type:      typeFunc(func(string) error { return nil }),
+		Predictor: args.PredictFiles(pattern),
+	}
+}
+
+// Dir accepts any value, and predicts the directories.
+func Dir() Type {
+	return predictedType{
+		Type:      typeFunc(func(string) error { return nil }),
+		Predictor: args.PredictDirs(""),
+	}
+}
+
+// Choice accepts one of the choices, which are also predicted.
+func Choice(choices ...string) Type {
+	return predictedType{
+		Type: typeFunc(func(v string) error {
+			for _, choice := range choices {
+				if v == choice {
+					return nil
+				}
+			}
+			return errors.Errorf("must be one of %s", strings.Join(choices, ", "))
+		}),
+		Predictor: args.PredictSet(choices...),
+	}
+}
+
+type typeFunc func(string) error
+
+func (t typeFunc) Validate(v string) error {
+	return t(v)
+}
+
+// predictedType is a Type that also predicts its values.
+type predictedType struct {
+	Type
+	args.Predictor
+}
+
+// unwrapNum removes the function and value from strconv errors, as the value
+// is already part of the argument error.
+func unwrapNum(err error) error {
+	if e, ok := err.(*strconv.NumError); ok {
+		return e.Err
+	}
+	return err
+}
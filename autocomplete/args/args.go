@@ -17,6 +17,15 @@ type FileSystem interface {
 	Getwd() (string, error)
 }
 
+// DirFileSystem is a FileSystem that is also able to read directories.
+type DirFileSystem interface {
+	FileSystem
+
+	// ReadDir reads the directory named by dirname and returns a list of
+	// directory entries sorted by filename.
+	ReadDir(string) ([]os.FileInfo, error)
+}
+
 // Args describes command line arguments
 type Args struct {
 	fs FileSystem
@@ -108,6 +117,11 @@ func (a *Args) CompletedCommands() (c []string) {
 	return
 }
 
+// Completed returns all the completed arguments, including the flags.
+func (a *Args) Completed() []string {
+	return a.completed
+}
+
 // Directory gives the directory of the current written
 // last argument if it represents a file name being written.
 // in case that it is not, we fall back to the current directory.
@@ -154,9 +168,11 @@ func (a *Args) fixPathForm(last string, file string) string {
 		return file
 	}
 
-	abs, err := filepath.Abs(file)
-	if err != nil {
-		return file
+	// resolve relative names against the working directory of the
+	// filesystem, which isn't always the process working directory.
+	abs := filepath.Clean(file)
+	if !filepath.IsAbs(abs) {
+		abs = filepath.Join(workDir, abs)
 	}
 
 	// if last is absolute, return path as absolute
@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/spoke-d/clui/autocomplete/args (interfaces: FileSystem)
+// Source: github.com/spoke-d/clui/autocomplete/args (interfaces: FileSystem,DirFileSystem)
 
 // Package args is a generated GoMock package.
 package args
@@ -62,3 +62,71 @@ func (mr *MockFileSystemMockRecorder) Stat(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stat", reflect.TypeOf((*MockFileSystem)(nil).Stat), arg0)
 }
+
+// MockDirFileSystem is a mock of DirFileSystem interface
+type MockDirFileSystem struct {
+	ctrl     *gomock.Controller
+	recorder *MockDirFileSystemMockRecorder
+}
+
+// MockDirFileSystemMockRecorder is the mock recorder for MockDirFileSystem
+type MockDirFileSystemMockRecorder struct {
+	mock *MockDirFileSystem
+}
+
+// NewMockDirFileSystem creates a new mock instance
+func NewMockDirFileSystem(ctrl *gomock.Controller) *MockDirFileSystem {
+	mock := &MockDirFileSystem{ctrl: ctrl}
+	mock.recorder = &MockDirFileSystemMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockDirFileSystem) EXPECT() *MockDirFileSystemMockRecorder {
+	return m.recorder
+}
+
+// Getwd mocks base method
+func (m *MockDirFileSystem) Getwd() (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Getwd")
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Getwd indicates an expected call of Getwd
+func (mr *MockDirFileSystemMockRecorder) Getwd() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Getwd", reflect.TypeOf((*MockDirFileSystem)(nil).Getwd))
+}
+
+// ReadDir mocks base method
+func (m *MockDirFileSystem) ReadDir(arg0 string) ([]os.FileInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadDir", arg0)
+	ret0, _ := ret[0].([]os.FileInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadDir indicates an expected call of ReadDir
+func (mr *MockDirFileSystemMockRecorder) ReadDir(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadDir", reflect.TypeOf((*MockDirFileSystem)(nil).ReadDir), arg0)
+}
+
+// Stat mocks base method
+func (m *MockDirFileSystem) Stat(arg0 string) (os.FileInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stat", arg0)
+	ret0, _ := ret[0].(os.FileInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Stat indicates an expected call of Stat
+func (mr *MockDirFileSystemMockRecorder) Stat(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stat", reflect.TypeOf((*MockDirFileSystem)(nil).Stat), arg0)
+}
@@ -0,0 +1,98 @@
+package args
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Predictor predicts the possible values of an argument that is being typed.
+type Predictor interface {
+	Predict(*Args) []string
+}
+
+// PredictFunc is a function that implements the Predictor interface.
+type PredictFunc func(*Args) []string
+
+// Predict calls the function with the args.
+func (f PredictFunc) Predict(a *Args) []string {
+	return f(a)
+}
+
+// PredictSet returns a Predictor that predicts a static set of values.
+func PredictSet(values ...string) Predictor {
+	return PredictFunc(func(*Args) []string {
+		return values
+	})
+}
+
+// PredictFiles returns a Predictor that predicts the files matching the glob
+// pattern, such as "*.yaml", along with the directories to descend into.
+func PredictFiles(pattern string) Predictor {
+	return PredictFunc(func(a *Args) []string {
+		return a.files(pattern, true)
+	})
+}
+
+// PredictDirs returns a Predictor that predicts the directories matching the
+// glob pattern. An empty pattern matches every directory.
+func PredictDirs(pattern string) Predictor {
+	return PredictFunc(func(a *Args) []string {
+		return a.files(pattern, false)
+	})
+}
+
+// files lists the entries of the directory being typed. Directories are
+// always listed, so that they can be descended into, unless the pattern
+// doesn't match when only directories are allowed.
+func (a *Args) files(pattern string, allowFiles bool) []string {
+	if a.fs == nil || strings.HasSuffix(a.last, "/..") {
+		return nil
+	}
+
+	dir := a.Directory()
+	infos, err := readDir(a.fs, dir)
+	if err != nil {
+		return nil
+	}
+
+	// Keep the form that is being typed, so that the values match the last
+	// argument.
+	prefix := dir
+	if dir == "./" && !strings.HasPrefix(a.last, "./") {
+		prefix = ""
+	}
+
+	var res []string
+	for _, info := range infos {
+		name := info.Name()
+		if info.IsDir() {
+			if allowFiles || match(pattern, name) {
+				res = append(res, prefix+name+"/")
+			}
+			continue
+		}
+		if allowFiles && match(pattern, name) {
+			res = append(res, prefix+name)
+		}
+	}
+	return res
+}
+
+// readDir reads the directory with the FileSystem, if it's a DirFileSystem,
+// otherwise the directory is read from the local disk.
+func readDir(fs FileSystem, path string) ([]os.FileInfo, error) {
+	if dirs, ok := fs.(DirFileSystem); ok {
+		return dirs.ReadDir(path)
+	}
+	return ioutil.ReadDir(path)
+}
+
+func match(pattern, name string) bool {
+	if pattern == "" {
+		return true
+	}
+	ok, err := filepath.Match(pattern, name)
+	return err == nil && ok
+}
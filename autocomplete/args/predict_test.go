@@ -0,0 +1,76 @@
+package args
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+func TestPredictFiles(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		predictor Predictor
+		line      string
+		expected  []string
+	}{
+		{
+			name:      "files",
+			predictor: PredictFiles(""),
+			line:      "a b ",
+			expected:  []string{"conf/", "config.yaml", "notes.txt"},
+		},
+		{
+			name:      "files with pattern",
+			predictor: PredictFiles("*.yaml"),
+			line:      "a b con",
+			expected:  []string{"conf/", "config.yaml"},
+		},
+		{
+			name:      "files with dot prefix",
+			predictor: PredictFiles("*.yaml"),
+			line:      "a b ./",
+			expected:  []string{"./conf/", "./config.yaml"},
+		},
+		{
+			name:      "dirs",
+			predictor: PredictDirs(""),
+			line:      "a b ",
+			expected:  []string{"conf/"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			dir := NewMockFileInfo(ctrl)
+			dir.EXPECT().IsDir().Return(true).AnyTimes()
+			dir.EXPECT().Name().Return("conf").AnyTimes()
+
+			config := NewMockFileInfo(ctrl)
+			config.EXPECT().IsDir().Return(false).AnyTimes()
+			config.EXPECT().Name().Return("config.yaml").AnyTimes()
+
+			notes := NewMockFileInfo(ctrl)
+			notes.EXPECT().IsDir().Return(false).AnyTimes()
+			notes.EXPECT().Name().Return("notes.txt").AnyTimes()
+
+			fs := NewMockDirFileSystem(ctrl)
+			fs.EXPECT().Stat(".").Return(dir, nil).AnyTimes()
+			fs.EXPECT().Stat(gomock.Any()).Return(nil, errors.New("not found")).AnyTimes()
+			fs.EXPECT().Getwd().Return("/work", nil).AnyTimes()
+			fs.EXPECT().ReadDir("./").Return([]os.FileInfo{dir, config, notes}, nil)
+
+			a := New(tt.line, OptionFileSystem(fs))
+			if expected, actual := tt.expected, tt.predictor.Predict(a); !reflect.DeepEqual(expected, actual) {
+				t.Errorf("expected: %v, actual: %v", expected, actual)
+			}
+		})
+	}
+}
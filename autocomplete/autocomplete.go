@@ -5,15 +5,14 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/spoke-d/clui/argset"
 	"github.com/spoke-d/clui/autocomplete/args"
+	"github.com/spoke-d/clui/autocomplete/fsys"
 	"github.com/spoke-d/clui/flagset"
 	"github.com/spoke-d/clui/radix"
 )
 
-const (
-	envComplete = "COMP_LINE"
-	envDebug    = "COMP_DEBUG"
-)
+const envDebug = "COMP_DEBUG"
 
 // Installer is an interface to be implemented to perform the autocomplete
 // installation and un-installation with a CLI.
@@ -29,11 +28,56 @@ type Installer interface {
 // the prefix provided.
 type Group interface {
 	WalkPrefix(string, radix.WalkFn)
+	Hidden(string) bool
 }
 
 // Command represents an abstraction of command.
 type Command interface {
 	FlagSet() *flagset.FlagSet
+	Synopsis() string
+}
+
+// Completer is an optional interface that a Command can implement, to
+// complete the positional arguments of the command.
+type Completer interface {
+	// Complete returns the possible values for the argument that is being
+	// typed. The args start after the command path. The predictors, such as
+	// args.PredictFiles, can be used for completing files.
+	Complete(*args.Args) []string
+}
+
+// Positional is an optional interface that a Command can implement, to
+// complete the positional arguments from the specification of them. Completer
+// takes precedence over Positional.
+type Positional interface {
+	// ArgSet returns the specification of the positional arguments.
+	ArgSet() *argset.ArgSet
+}
+
+// External is an optional interface that a Command can implement, when it's
+// run outside of the CLI, such as a plugin. External commands aren't described
+// when completing, as describing them can require running them.
+type External interface {
+	// External returns if the command is external.
+	External() bool
+}
+
+// Candidate is a possible completion, along with a description of what it
+// completes to.
+type Candidate struct {
+	Value       string
+	Description string
+}
+
+// Describe returns the candidate in the "value:description" style that is
+// used by zsh's _describe.
+func (c Candidate) Describe() string {
+	value := strings.Replace(c.Value, ":", `\:`, -1)
+	description := strings.Join(strings.Fields(c.Description), " ")
+	if description == "" {
+		return value
+	}
+	return fmt.Sprintf("%s:%s", value, description)
 }
 
 // AutoCompleteOptions represents a way to set optional values to a autocomplete
@@ -42,14 +86,18 @@ type Command interface {
 type AutoCompleteOptions interface {
 	SetInstaller(Installer)
 	SetGroup(Group)
+	SetGlobalFlags(*flagset.FlagSet)
+	SetFileSystem(args.FileSystem)
 }
 
 // AutoCompleteOption captures a tweak that can be applied to the AutoComplete.
 type AutoCompleteOption func(AutoCompleteOptions)
 
 type autocomplete struct {
-	installer Installer
-	group     Group
+	installer   Installer
+	group       Group
+	globalFlags *flagset.FlagSet
+	fileSystem  args.FileSystem
 }
 
 func (s *autocomplete) SetInstaller(i Installer) {
@@ -60,6 +108,14 @@ func (s *autocomplete) SetGroup(g Group) {
 	s.group = g
 }
 
+func (s *autocomplete) SetGlobalFlags(f *flagset.FlagSet) {
+	s.globalFlags = f
+}
+
+func (s *autocomplete) SetFileSystem(fs args.FileSystem) {
+	s.fileSystem = fs
+}
+
 // OptionInstaller allows the setting a installer option to configure
 // the autocomplete.
 func OptionInstaller(i Installer) AutoCompleteOption {
@@ -76,23 +132,45 @@ func OptionGroup(g Group) AutoCompleteOption {
 	}
 }
 
+// OptionGlobalFlags allows the setting a global flags option to configure
+// the autocomplete.
+func OptionGlobalFlags(f *flagset.FlagSet) AutoCompleteOption {
+	return func(opt AutoCompleteOptions) {
+		opt.SetGlobalFlags(f)
+	}
+}
+
+// OptionFileSystem allows the setting a filesystem option to configure
+// the autocomplete. The filesystem is used for predicting files.
+func OptionFileSystem(fs args.FileSystem) AutoCompleteOption {
+	return func(opt AutoCompleteOptions) {
+		opt.SetFileSystem(fs)
+	}
+}
+
 // AutoComplete defines a way to predict and complete arguments passed
 // in to the CLI
 type AutoComplete struct {
-	installer Installer
-	group     Group
+	installer   Installer
+	group       Group
+	globalFlags *flagset.FlagSet
+	fileSystem  args.FileSystem
 }
 
 // New creates a new AutoComplete with the correct dependencies.
 func New(options ...AutoCompleteOption) *AutoComplete {
-	opt := new(autocomplete)
+	opt := &autocomplete{
+		fileSystem: fsys.NewLocalFileSystem(),
+	}
 	for _, option := range options {
 		option(opt)
 	}
 
 	return &AutoComplete{
-		installer: opt.installer,
-		group:     opt.group,
+		installer:   opt.installer,
+		group:       opt.group,
+		globalFlags: opt.globalFlags,
+		fileSystem:  opt.fileSystem,
 	}
 }
 
@@ -112,7 +190,7 @@ func (a *AutoComplete) Uninstall(cmd string) error {
 // and print out the complete options.
 // Returns success if the completion ran or if the cli matched
 // any of the given flags, false otherwise
-func (a *AutoComplete) Complete(line string) ([]string, bool) {
+func (a *AutoComplete) Complete(line string) ([]Candidate, bool) {
 	// If the line is empty, don't even attempt to complete on it.
 	if line == "" {
 		return nil, false
@@ -120,14 +198,14 @@ func (a *AutoComplete) Complete(line string) ([]string, bool) {
 
 	// If we've got something attempt to predict the command.
 	var (
-		matches []string
+		matches []Candidate
 
-		args    = args.New(line)
+		args    = args.New(line, args.OptionFileSystem(a.fileSystem))
 		options = a.Predict(args)
 	)
 
 	for _, opt := range options {
-		if strings.HasPrefix(opt, args.Last()) {
+		if strings.HasPrefix(opt.Value, args.Last()) {
 			matches = append(matches, opt)
 		}
 	}
@@ -136,13 +214,23 @@ func (a *AutoComplete) Complete(line string) ([]string, bool) {
 }
 
 // Predict returns all possible predictions for args according to the command.
-func (a *AutoComplete) Predict(v *args.Args) []string {
+func (a *AutoComplete) Predict(v *args.Args) []Candidate {
 	var (
-		options   []string
+		options   []Candidate
 		potential []pair
 		args      = strings.Join(v.AllCommands(), " ")
 	)
+
+	// If the last completed argument is a flag that takes a value, only
+	// complete the value of the flag.
+	if values, ok := a.predictFlagValue(v); ok {
+		return values
+	}
+
 	a.group.WalkPrefix(args, func(s string, cmd radix.Value) bool {
+		if a.group.Hidden(s) {
+			return false
+		}
 		if c, ok := cmd.(Command); ok {
 			potential = append(potential, pair{
 				Name:    s,
@@ -153,55 +241,194 @@ func (a *AutoComplete) Predict(v *args.Args) []string {
 		return false
 	})
 
-	if len(potential) > 0 {
-		if isFlag := strings.HasPrefix(v.Last(), "-"); isFlag {
-			// Check if the potential cmd is an exact match
-			var pairs []pair
-			for _, pair := range potential {
-				if pair.Name == strings.Join(v.AllCommands(), " ") {
-					pairs = append(pairs, pair)
-				}
+	if isFlag := strings.HasPrefix(v.Last(), "-"); isFlag {
+		// Check if the potential cmd is an exact match
+		var pairs []pair
+		for _, pair := range potential {
+			if pair.Name == strings.Join(v.AllCommands(), " ") {
+				pairs = append(pairs, pair)
 			}
+		}
 
-			// find out what those flags are
-			for _, pair := range pairs {
-				opts, only := predictFlag(pair.Command, v)
-				if only {
-					return opts
-				}
-				options = append(options, opts...)
+		// find out what those flags are
+		for _, pair := range pairs {
+			opts, only := predictFlag(pair.Command, v)
+			if only {
+				return opts
 			}
-		} else {
-			// auto complete the command name
-			for _, pair := range potential {
-				parts := strings.Split(pair.Name, " ")
-				if len(parts) >= 1 {
-					options = append(options, parts[len(parts)-1])
-				}
+			options = append(options, opts...)
+		}
+
+		// global flags are available for every command
+		if a.globalFlags != nil {
+			opts, only := predictFlagSet(a.globalFlags, v)
+			if only {
+				return opts
+			}
+			options = append(options, opts...)
+		}
+	} else if len(potential) > 0 {
+		// auto complete the command name
+		for _, pair := range potential {
+			parts := strings.Split(pair.Name, " ")
+			if len(parts) >= 1 {
+				options = append(options, Candidate{
+					Value:       parts[len(parts)-1],
+					Description: describe(pair.Command),
+				})
 			}
 		}
-	} else {
-		// TODO: auto complete files
+	} else if _, cmd, offset, ok := a.resolve(v); ok {
+		// The command path is fully resolved, so complete the positional
+		// arguments of the command.
+		var values []string
+		if completer, ok := cmd.(Completer); ok {
+			values = completer.Complete(v.From(offset))
+		} else if positional, ok := cmd.(Positional); ok {
+			values = positional.ArgSet().Complete(v.From(offset), cmd.FlagSet())
+		}
+		for _, value := range values {
+			options = append(options, Candidate{
+				Value: value,
+			})
+		}
 	}
 	return options
 }
 
+// predictFlagValue predicts the value of a flag, when the argument being typed
+// is the value of the flag, either as "--flag value" or "--flag=value".
+func (a *AutoComplete) predictFlagValue(v *args.Args) ([]Candidate, bool) {
+	last := v.LastCompleted()
+	if !strings.HasPrefix(last, "-") {
+		return nil, false
+	}
+	name := strings.TrimLeft(last, "-")
+
+	var flagSets []*flagset.FlagSet
+	if _, cmd, _, ok := a.resolve(v); ok {
+		flagSets = append(flagSets, cmd.FlagSet())
+	}
+	if a.globalFlags != nil {
+		flagSets = append(flagSets, a.globalFlags)
+	}
+
+	for _, flagSet := range flagSets {
+		f := flagSet.Lookup(name)
+		if f == nil {
+			continue
+		}
+		// Boolean flags don't take a value, unless it's passed with "=".
+		if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+			return nil, false
+		}
+
+		var options []Candidate
+		if predictor, ok := flagSet.Predictor(f.Name); ok {
+			for _, value := range predictor.Predict(v) {
+				options = append(options, Candidate{
+					Value: value,
+				})
+			}
+		}
+		return options, true
+	}
+	return nil, false
+}
+
+// resolve returns the command for the longest path of completed commands,
+// along with the offset of the arguments that follow the command path.
+func (a *AutoComplete) resolve(v *args.Args) (string, Command, int, bool) {
+	words, offsets := a.commandWords(v)
+	for i := len(words); i > 0; i-- {
+		var (
+			key = strings.Join(words[:i], " ")
+			cmd Command
+		)
+		a.group.WalkPrefix(key, func(s string, value radix.Value) bool {
+			if s != key {
+				return false
+			}
+			cmd, _ = value.(Command)
+			return true
+		})
+		if cmd != nil {
+			return key, cmd, offsets[i-1] + 1, true
+		}
+	}
+	return "", nil, 0, false
+}
+
+// commandWords returns the completed arguments that aren't flags, or values
+// of global flags, along with their offsets in the completed arguments.
+func (a *AutoComplete) commandWords(v *args.Args) ([]string, []int) {
+	var (
+		words   []string
+		offsets []int
+	)
+	completed := v.Completed()
+	for i := 0; i < len(completed); i++ {
+		arg := completed[i]
+		if !strings.HasPrefix(arg, "-") {
+			words = append(words, arg)
+			offsets = append(offsets, i)
+			continue
+		}
+		if a.globalFlags == nil || strings.Contains(arg, "=") {
+			continue
+		}
+		f := a.globalFlags.Lookup(strings.TrimLeft(arg, "-"))
+		if f == nil {
+			continue
+		}
+		if b, ok := f.Value.(interface{ IsBoolFlag() bool }); !ok || !b.IsBoolFlag() {
+			i++
+		}
+	}
+	return words, offsets
+}
+
+// describe returns the synopsis of the command, unless it's external.
+func describe(cmd Command) string {
+	if ext, ok := cmd.(External); ok && ext.External() {
+		return ""
+	}
+	return cmd.Synopsis()
+}
+
 type pair struct {
 	Name    string
 	Command Command
 }
 
-func predictFlag(cmd Command, a *args.Args) ([]string, bool) {
-	flagset := cmd.FlagSet()
+func predictFlag(cmd Command, a *args.Args) ([]Candidate, bool) {
+	return predictFlagSet(cmd.FlagSet(), a)
+}
+
+func predictFlagSet(flagset *flagset.FlagSet, a *args.Args) ([]Candidate, bool) {
 	flagName := strings.TrimLeft(strings.TrimSpace(a.Last()), "-")
 	if flag := flagset.Lookup(flagName); flag != nil {
-		return []string{fmt.Sprintf("--%s", flag.Name)}, true
+		if flagName == flagset.Short(flag.Name) {
+			return []Candidate{flagCandidate(fmt.Sprintf("-%s", flagName), flag)}, true
+		}
+		return []Candidate{flagCandidate(fmt.Sprintf("--%s", flag.Name), flag)}, true
 	}
 
-	var options []string
+	var options []Candidate
 	flagset.VisitAll(func(f *flag.Flag) {
-		options = append(options, fmt.Sprintf("--%s", f.Name))
+		options = append(options, flagCandidate(fmt.Sprintf("--%s", f.Name), f))
+		if short := flagset.Short(f.Name); short != "" {
+			options = append(options, flagCandidate(fmt.Sprintf("-%s", short), f))
+		}
 	})
 
 	return options, false
 }
+
+func flagCandidate(name string, f *flag.Flag) Candidate {
+	_, usage := flag.UnquoteUsage(f)
+	return Candidate{
+		Value:       name,
+		Description: usage,
+	}
+}
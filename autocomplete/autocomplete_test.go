@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/golang/mock/gomock"
+	"github.com/spoke-d/clui/autocomplete/args"
 	"github.com/spoke-d/clui/flagset"
 	"github.com/spoke-d/clui/radix"
 )
@@ -30,17 +31,21 @@ func TestAutoCompleteCommand(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
+		cmd := NewMockCommand(ctrl)
+		cmd.EXPECT().Synopsis().Return("Foo things.")
+
 		group := NewMockGroup(ctrl)
 		group.EXPECT().WalkPrefix("test foo", gomock.Any()).Do(func(s string, fn func(s string, cmd radix.Value) bool) {
-			fn(s, NewMockCommand(ctrl))
+			fn(s, cmd)
 		})
+		group.EXPECT().Hidden("test foo").Return(false)
 
 		ac := New(OptionGroup(group))
 		matches, ok := ac.Complete("clui test foo")
 		if expected, actual := true, ok; expected != actual {
 			t.Errorf("expected: %v, actual: %v", expected, actual)
 		}
-		if expected, actual := []string{"foo"}, matches; !reflect.DeepEqual(expected, actual) {
+		if expected, actual := []Candidate{{Value: "foo", Description: "Foo things."}}, matches; !reflect.DeepEqual(expected, actual) {
 			t.Errorf("expected: %v, actual: %v", expected, actual)
 		}
 	})
@@ -49,22 +54,81 @@ func TestAutoCompleteCommand(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 
+		cmd := NewMockCommand(ctrl)
+		cmd.EXPECT().Synopsis().Return("Bar things.")
+
 		group := NewMockGroup(ctrl)
 		group.EXPECT().WalkPrefix("test foo ", gomock.Any()).Do(func(s string, fn func(s string, cmd radix.Value) bool) {
-			fn("bar", NewMockCommand(ctrl))
+			fn("bar", cmd)
 		})
+		group.EXPECT().Hidden("bar").Return(false)
 
 		ac := New(OptionGroup(group))
 		matches, ok := ac.Complete("clui test foo ")
 		if expected, actual := true, ok; expected != actual {
 			t.Errorf("expected: %v, actual: %v", expected, actual)
 		}
-		if expected, actual := []string{"bar"}, matches; !reflect.DeepEqual(expected, actual) {
+		if expected, actual := []Candidate{{Value: "bar", Description: "Bar things."}}, matches; !reflect.DeepEqual(expected, actual) {
 			t.Errorf("expected: %v, actual: %v", expected, actual)
 		}
 	})
 }
 
+func TestAutoCompleteHidden(t *testing.T) {
+	t.Parallel()
+
+	t.Run("complete", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		cmd := NewMockCommand(ctrl)
+		cmd.EXPECT().Synopsis().Return("Bar things.")
+
+		group := NewMockGroup(ctrl)
+		group.EXPECT().WalkPrefix("test ", gomock.Any()).Do(func(s string, fn func(s string, cmd radix.Value) bool) {
+			fn("test foo", NewMockCommand(ctrl))
+			fn("test bar", cmd)
+		})
+		group.EXPECT().Hidden("test foo").Return(true)
+		group.EXPECT().Hidden("test bar").Return(false)
+
+		ac := New(OptionGroup(group))
+		matches, ok := ac.Complete("clui test ")
+		if expected, actual := true, ok; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := []Candidate{{Value: "bar", Description: "Bar things."}}, matches; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("complete external", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		group := NewMockGroup(ctrl)
+		group.EXPECT().WalkPrefix("test ", gomock.Any()).Do(func(s string, fn func(s string, cmd radix.Value) bool) {
+			fn("test bar", externalCommand{NewMockCommand(ctrl)})
+		})
+		group.EXPECT().Hidden("test bar").Return(false)
+
+		ac := New(OptionGroup(group))
+		matches, ok := ac.Complete("clui test ")
+		if expected, actual := true, ok; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := []Candidate{{Value: "bar"}}, matches; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+}
+
+type externalCommand struct {
+	*MockCommand
+}
+
+func (externalCommand) External() bool { return true }
+
 func TestAutoCompleteFlagset(t *testing.T) {
 	t.Parallel()
 
@@ -82,13 +146,14 @@ func TestAutoCompleteFlagset(t *testing.T) {
 		group.EXPECT().WalkPrefix("test foo", gomock.Any()).Do(func(s string, fn func(s string, cmd radix.Value) bool) {
 			fn(s, cmd)
 		})
+		group.EXPECT().Hidden("test foo").Return(false)
 
 		ac := New(OptionGroup(group))
 		matches, ok := ac.Complete("clui test foo --bar")
 		if expected, actual := true, ok; expected != actual {
 			t.Errorf("expected: %v, actual: %v", expected, actual)
 		}
-		if expected, actual := []string{"--bar"}, matches; !reflect.DeepEqual(expected, actual) {
+		if expected, actual := []Candidate{{Value: "--bar", Description: "some usage pattern here"}}, matches; !reflect.DeepEqual(expected, actual) {
 			t.Errorf("expected: %v, actual: %v", expected, actual)
 		}
 	})
@@ -108,14 +173,262 @@ func TestAutoCompleteFlagset(t *testing.T) {
 		group.EXPECT().WalkPrefix("test foo", gomock.Any()).Do(func(s string, fn func(s string, cmd radix.Value) bool) {
 			fn(s, cmd)
 		})
+		group.EXPECT().Hidden("test foo").Return(false)
 
 		ac := New(OptionGroup(group))
 		matches, ok := ac.Complete("clui test foo --ba")
 		if expected, actual := true, ok; expected != actual {
 			t.Errorf("expected: %v, actual: %v", expected, actual)
 		}
-		if expected, actual := []string{"--bar", "--baz"}, matches; !reflect.DeepEqual(expected, actual) {
+		if expected, actual := []Candidate{
+			{Value: "--bar", Description: "some usage pattern here"},
+			{Value: "--baz", Description: "some usage pattern here"},
+		}, matches; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("complete global flags", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		flagSet := flagset.New("test", flag.ContinueOnError)
+		flagSet.String("bar", "false", "some usage pattern here")
+
+		globalFlags := flagset.New("global", flag.ContinueOnError)
+		globalFlags.String("profile", "", "some usage pattern here")
+
+		cmd := NewMockCommand(ctrl)
+		cmd.EXPECT().FlagSet().Return(flagSet)
+
+		group := NewMockGroup(ctrl)
+		group.EXPECT().WalkPrefix("test foo", gomock.Any()).Do(func(s string, fn func(s string, cmd radix.Value) bool) {
+			fn(s, cmd)
+		})
+		group.EXPECT().Hidden("test foo").Return(false)
+
+		ac := New(OptionGroup(group), OptionGlobalFlags(globalFlags))
+		matches, ok := ac.Complete("clui test foo --")
+		if expected, actual := true, ok; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := []Candidate{
+			{Value: "--bar", Description: "some usage pattern here"},
+			{Value: "--profile", Description: "some usage pattern here"},
+		}, matches; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("complete short flags", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		var output string
+		flagSet := flagset.New("test", flag.ContinueOnError)
+		flagSet.StringVarP(&output, "output", "o", "", "some usage pattern here")
+
+		cmd := NewMockCommand(ctrl)
+		cmd.EXPECT().FlagSet().Return(flagSet).Times(2)
+
+		group := NewMockGroup(ctrl)
+		group.EXPECT().WalkPrefix("test foo", gomock.Any()).Do(func(s string, fn func(s string, cmd radix.Value) bool) {
+			fn(s, cmd)
+		}).Times(2)
+		group.EXPECT().Hidden("test foo").Return(false).Times(2)
+
+		ac := New(OptionGroup(group))
+		matches, ok := ac.Complete("clui test foo -")
+		if expected, actual := true, ok; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := []Candidate{
+			{Value: "--output", Description: "some usage pattern here"},
+			{Value: "-o", Description: "some usage pattern here"},
+		}, matches; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+
+		matches, _ = ac.Complete("clui test foo -o")
+		if expected, actual := []Candidate{{Value: "-o", Description: "some usage pattern here"}}, matches; !reflect.DeepEqual(expected, actual) {
 			t.Errorf("expected: %v, actual: %v", expected, actual)
 		}
 	})
 }
+
+func TestAutoCompleteFlagValue(t *testing.T) {
+	t.Parallel()
+
+	for _, line := range []string{
+		"clui test foo --format j",
+		"clui test foo --format=j",
+	} {
+		t.Run(line, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			flagSet := flagset.New("test", flag.ContinueOnError)
+			flagSet.String("format", "text", "some usage pattern here")
+			flagSet.SetPredictor("format", args.PredictSet("text", "json", "jsonl"))
+
+			cmd := NewMockCommand(ctrl)
+			cmd.EXPECT().FlagSet().Return(flagSet)
+
+			group := NewMockGroup(ctrl)
+			group.EXPECT().WalkPrefix("test foo", gomock.Any()).Do(func(s string, fn func(s string, cmd radix.Value) bool) {
+				fn(s, cmd)
+			})
+
+			ac := New(OptionGroup(group))
+			matches, ok := ac.Complete(line)
+			if expected, actual := true, ok; expected != actual {
+				t.Errorf("expected: %v, actual: %v", expected, actual)
+			}
+			if expected, actual := []Candidate{{Value: "json"}, {Value: "jsonl"}}, matches; !reflect.DeepEqual(expected, actual) {
+				t.Errorf("expected: %v, actual: %v", expected, actual)
+			}
+		})
+	}
+
+	t.Run("global flag without predictor", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		globalFlags := flagset.New("global", flag.ContinueOnError)
+		globalFlags.String("profile", "", "some usage pattern here")
+
+		group := NewMockGroup(ctrl)
+		group.EXPECT().WalkPrefix("test", gomock.Any())
+
+		ac := New(OptionGroup(group), OptionGlobalFlags(globalFlags))
+		matches, ok := ac.Complete("clui test --profile ")
+		if expected, actual := true, ok; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := 0, len(matches); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+}
+
+type completerCommand struct {
+	*MockCommand
+	values []string
+	args   *args.Args
+}
+
+func (c *completerCommand) Complete(a *args.Args) []string {
+	c.args = a
+	return c.values
+}
+
+func TestAutoCompleteCompleter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("complete", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		cmd := &completerCommand{
+			MockCommand: NewMockCommand(ctrl),
+			values:      []string{"foo", "bar", "food"},
+		}
+
+		group := NewMockGroup(ctrl)
+		gomock.InOrder(
+			group.EXPECT().WalkPrefix("config show fo", gomock.Any()),
+			group.EXPECT().WalkPrefix("config show", gomock.Any()).Do(func(s string, fn func(s string, cmd radix.Value) bool) {
+				fn(s, cmd)
+			}),
+		)
+
+		ac := New(OptionGroup(group))
+		matches, ok := ac.Complete("clui config show fo")
+		if expected, actual := true, ok; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := []Candidate{{Value: "foo"}, {Value: "food"}}, matches; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := "fo", cmd.args.Last(); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := 0, len(cmd.args.CompletedCommands()); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("complete after global flags", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		cmd := &completerCommand{
+			MockCommand: NewMockCommand(ctrl),
+			values:      []string{"foo", "bar", "food"},
+		}
+
+		globalFlags := flagset.New("global", flag.ContinueOnError)
+		globalFlags.Bool("debug", false, "some usage pattern here")
+		globalFlags.String("profile", "", "some usage pattern here")
+
+		group := NewMockGroup(ctrl)
+		gomock.InOrder(
+			group.EXPECT().WalkPrefix("prod config show a fo", gomock.Any()),
+			group.EXPECT().WalkPrefix("config show a", gomock.Any()),
+			group.EXPECT().WalkPrefix("config show", gomock.Any()).Do(func(s string, fn func(s string, cmd radix.Value) bool) {
+				fn(s, cmd)
+			}),
+		)
+
+		ac := New(OptionGroup(group), OptionGlobalFlags(globalFlags))
+		matches, ok := ac.Complete("clui --debug --profile prod config show a fo")
+		if expected, actual := true, ok; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := []Candidate{{Value: "foo"}, {Value: "food"}}, matches; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := []string{"a"}, cmd.args.CompletedCommands(); !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("complete without completer", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		group := NewMockGroup(ctrl)
+		gomock.InOrder(
+			group.EXPECT().WalkPrefix("config show ", gomock.Any()),
+			group.EXPECT().WalkPrefix("config show", gomock.Any()).Do(func(s string, fn func(s string, cmd radix.Value) bool) {
+				fn(s, NewMockCommand(ctrl))
+			}),
+		)
+
+		ac := New(OptionGroup(group))
+		matches, ok := ac.Complete("clui config show ")
+		if expected, actual := true, ok; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := 0, len(matches); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+}
+
+func TestCandidateDescribe(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		candidate Candidate
+		expected  string
+	}{
+		{candidate: Candidate{Value: "foo"}, expected: "foo"},
+		{candidate: Candidate{Value: "foo", Description: "Foo things."}, expected: "foo:Foo things."},
+		{candidate: Candidate{Value: "a:b", Description: "Foo\n  things."}, expected: `a\:b:Foo things.`},
+	} {
+		if expected, actual := tc.expected, tc.candidate.Describe(); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	}
+}
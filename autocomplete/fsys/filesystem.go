@@ -31,6 +31,24 @@ type FileSystem interface {
 	Remove(string) error
 }
 
+// DirFileSystem is a FileSystem that is also able to create directories.
+type DirFileSystem interface {
+	FileSystem
+
+	// MkdirAll takes a path and creates the directory, along with any parents
+	// that don't exist yet.
+	MkdirAll(string) error
+}
+
+// MkdirAll creates the directory with the FileSystem, if it's a DirFileSystem,
+// otherwise the directory is created on the local disk.
+func MkdirAll(fs FileSystem, path string) error {
+	if dirs, ok := fs.(DirFileSystem); ok {
+		return dirs.MkdirAll(path)
+	}
+	return os.MkdirAll(path, mkdirAllMode)
+}
+
 // File is an abstraction for reading, writing and also closing a file. These
 // interfaces already exist, it's just a matter of composing them to be more
 // usable by other components.
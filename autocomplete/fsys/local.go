@@ -2,6 +2,7 @@ package fsys
 
 import (
 	"io"
+	"io/ioutil"
 	"os"
 	"strings"
 
@@ -58,6 +59,28 @@ func (LocalFileSystem) Remove(path string) error {
 	return os.Remove(path)
 }
 
+// Stat returns a FileInfo describing the named file.
+func (LocalFileSystem) Stat(path string) (os.FileInfo, error) {
+	return os.Stat(path)
+}
+
+// Getwd returns a rooted path name corresponding to the current directory.
+func (LocalFileSystem) Getwd() (string, error) {
+	return os.Getwd()
+}
+
+// ReadDir reads the directory named by dirname and returns a list of
+// directory entries sorted by filename.
+func (LocalFileSystem) ReadDir(path string) ([]os.FileInfo, error) {
+	return ioutil.ReadDir(path)
+}
+
+// MkdirAll takes a path and creates the directory, along with any parents
+// that don't exist yet.
+func (LocalFileSystem) MkdirAll(path string) error {
+	return os.MkdirAll(path, mkdirAllMode)
+}
+
 func (fs LocalFileSystem) open(f *os.File, err error) (LocalFile, error) {
 	if err != nil {
 		if err == os.ErrNotExist {
@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/spoke-d/clui/autocomplete/fsys (interfaces: FileSystem,File)
+// Source: github.com/spoke-d/clui/autocomplete/fsys (interfaces: FileSystem,File,DirFileSystem)
 
 // Package install is a generated GoMock package.
 package install
@@ -215,3 +215,113 @@ func (mr *MockFileMockRecorder) Write(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockFile)(nil).Write), arg0)
 }
+
+// MockDirFileSystem is a mock of DirFileSystem interface
+type MockDirFileSystem struct {
+	ctrl     *gomock.Controller
+	recorder *MockDirFileSystemMockRecorder
+}
+
+// MockDirFileSystemMockRecorder is the mock recorder for MockDirFileSystem
+type MockDirFileSystemMockRecorder struct {
+	mock *MockDirFileSystem
+}
+
+// NewMockDirFileSystem creates a new mock instance
+func NewMockDirFileSystem(ctrl *gomock.Controller) *MockDirFileSystem {
+	mock := &MockDirFileSystem{ctrl: ctrl}
+	mock.recorder = &MockDirFileSystemMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockDirFileSystem) EXPECT() *MockDirFileSystemMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method
+func (m *MockDirFileSystem) Create(arg0 string) (fsys.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0)
+	ret0, _ := ret[0].(fsys.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create
+func (mr *MockDirFileSystemMockRecorder) Create(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockDirFileSystem)(nil).Create), arg0)
+}
+
+// Exists mocks base method
+func (m *MockDirFileSystem) Exists(arg0 string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Exists", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Exists indicates an expected call of Exists
+func (mr *MockDirFileSystemMockRecorder) Exists(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exists", reflect.TypeOf((*MockDirFileSystem)(nil).Exists), arg0)
+}
+
+// MkdirAll mocks base method
+func (m *MockDirFileSystem) MkdirAll(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MkdirAll", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MkdirAll indicates an expected call of MkdirAll
+func (mr *MockDirFileSystemMockRecorder) MkdirAll(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MkdirAll", reflect.TypeOf((*MockDirFileSystem)(nil).MkdirAll), arg0)
+}
+
+// Open mocks base method
+func (m *MockDirFileSystem) Open(arg0 string) (fsys.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Open", arg0)
+	ret0, _ := ret[0].(fsys.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Open indicates an expected call of Open
+func (mr *MockDirFileSystemMockRecorder) Open(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Open", reflect.TypeOf((*MockDirFileSystem)(nil).Open), arg0)
+}
+
+// OpenFile mocks base method
+func (m *MockDirFileSystem) OpenFile(arg0 string, arg1 int, arg2 os.FileMode) (fsys.File, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenFile", arg0, arg1, arg2)
+	ret0, _ := ret[0].(fsys.File)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OpenFile indicates an expected call of OpenFile
+func (mr *MockDirFileSystemMockRecorder) OpenFile(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenFile", reflect.TypeOf((*MockDirFileSystem)(nil).OpenFile), arg0, arg1, arg2)
+}
+
+// Remove mocks base method
+func (m *MockDirFileSystem) Remove(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Remove", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Remove indicates an expected call of Remove
+func (mr *MockDirFileSystemMockRecorder) Remove(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remove", reflect.TypeOf((*MockDirFileSystem)(nil).Remove), arg0)
+}
@@ -18,6 +18,18 @@ type Shell struct {
 	fsys  fsys.FileSystem
 	files []string
 	cmdFn func(string, string) string
+
+	// fileFn returns the path of a completion file for a command, when the
+	// shell loads completions from their own files, instead of a profile file.
+	fileFn func(string) string
+
+	// setupFn returns a line that is added to the profile file once, so that
+	// the shell can find the completion file for a command.
+	setupFn func(string) string
+
+	// legacyFn returns the line that earlier versions added to the profile
+	// file, which is removed when uninstalling.
+	legacyFn func(string, string) string
 }
 
 // Install attempts to install an autocomplete command into the correct file
@@ -26,6 +38,9 @@ type Shell struct {
 // is not possible.
 func (b *Shell) Install(cmd, bin string) error {
 	c := b.cmdFn(cmd, bin)
+	if b.fileFn != nil {
+		return b.installFile(cmd, c)
+	}
 	for _, f := range b.files {
 		if fileContains(b.fsys, f, c) {
 			return fmt.Errorf("file already contains line: %q", c)
@@ -43,7 +58,19 @@ func (b *Shell) Install(cmd, bin string) error {
 // files.
 // Returns an error if it's unable to modify the profile file.
 func (b *Shell) Uninstall(cmd, bin string) error {
+	if b.legacyFn != nil {
+		if err := b.uninstallLine(b.legacyFn(cmd, bin)); err != nil {
+			return err
+		}
+	}
 	c := b.cmdFn(cmd, bin)
+	if b.fileFn != nil {
+		return b.uninstallFile(cmd, c)
+	}
+	return b.uninstallLine(c)
+}
+
+func (b *Shell) uninstallLine(c string) error {
 	for _, f := range b.files {
 		if !fileContains(b.fsys, f, c) {
 			continue
@@ -56,6 +83,68 @@ func (b *Shell) Uninstall(cmd, bin string) error {
 	return nil
 }
 
+func (b *Shell) installFile(cmd, content string) error {
+	if len(b.files) == 0 {
+		return nil
+	}
+	path := b.fileFn(cmd)
+	if b.fsys.Exists(path) {
+		return fmt.Errorf("completion file already exists: %q", path)
+	}
+	if err := fsys.MkdirAll(b.fsys, filepath.Dir(path)); err != nil {
+		return err
+	}
+	f, err := b.fsys.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := f.Write([]byte(fmt.Sprintf("%s\n", content))); err != nil {
+		return err
+	}
+
+	if b.setupFn == nil {
+		return nil
+	}
+	setup := b.setupFn(cmd)
+	for _, rc := range b.files {
+		if fileContains(b.fsys, rc, setup) {
+			return nil
+		}
+	}
+	return appendToFile(b.fsys, b.files[0], setup)
+}
+
+func (b *Shell) uninstallFile(cmd, content string) error {
+	path := b.fileFn(cmd)
+	if !fileContains(b.fsys, path, content) {
+		return nil
+	}
+	if err := b.fsys.Remove(path); err != nil {
+		return err
+	}
+
+	// Only remove the setup line if it's dedicated to the completion file,
+	// otherwise it's shared with other commands.
+	if b.setupFn == nil {
+		return nil
+	}
+	setup := b.setupFn(cmd)
+	if !strings.Contains(setup, path) {
+		return nil
+	}
+	for _, rc := range b.files {
+		if !fileContains(b.fsys, rc, setup) {
+			continue
+		}
+		if err := removeFromFile(b.fsys, rc, setup); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // User is an abstraction around the current User.
 type User interface {
 
@@ -132,8 +221,10 @@ func Bash(options ...ShellOption) *Shell {
 	}
 }
 
-// Zsh creates a new Shell with the file locations and the correct zsh complete
-// command.
+// Zsh creates a new Shell that writes a native completion function for the
+// command into a directory, which is added to the fpath in the zsh profile
+// file. The profile is expected to run compinit, as most do. The candidates
+// are shown along with their descriptions.
 func Zsh(options ...ShellOption) *Shell {
 	opt := new(shell)
 	for _, option := range options {
@@ -150,15 +241,128 @@ func Zsh(options ...ShellOption) *Shell {
 		}
 	}
 
+	dir := filepath.Join(opt.user.HomeDir(), ".zsh", "completions")
+
 	return &Shell{
 		fsys:  opt.fileSystem,
 		files: files,
 		cmdFn: func(cmd, bin string) string {
+			return fmt.Sprintf(zshCompletion, cmd, bin)
+		},
+		fileFn: func(cmd string) string {
+			return filepath.Join(dir, fmt.Sprintf("_%s", cmd))
+		},
+		setupFn: func(string) string {
+			return fmt.Sprintf("(( ${fpath[(Ie)%[1]s]} )) || fpath=(%[1]s $fpath)", dir)
+		},
+		legacyFn: func(cmd, bin string) string {
 			return fmt.Sprintf("complete -o nospace -C %s %s", bin, cmd)
 		},
 	}
 }
 
+const zshCompletion = `#compdef %[1]s
+
+_%[1]s() {
+    local -a candidates
+    candidates=("${(@f)$(COMP_LINE="${words[1,CURRENT]}" COMP_DESCRIBE=1 %[2]s 2>/dev/null)}")
+    _describe 'values' candidates
+}
+
+if [ "$funcstack[1]" = "_%[1]s" ]; then
+    _%[1]s "$@"
+else
+    compdef _%[1]s %[1]s
+fi`
+
+// Fish creates a new Shell that writes a completion file for the command into
+// the fish completions directory. The completions are only installed if the
+// fish config directory exists.
+func Fish(options ...ShellOption) *Shell {
+	opt := new(shell)
+	for _, option := range options {
+		option(opt)
+	}
+
+	dir := filepath.Join(".config", "fish")
+
+	var files []string
+	if path, ok := filePath(opt.fileSystem, opt.user, dir); ok {
+		files = append(files, path)
+	}
+
+	return &Shell{
+		fsys:  opt.fileSystem,
+		files: files,
+		cmdFn: func(cmd, bin string) string {
+			return fmt.Sprintf("complete -c %s -f -a '(env COMP_LINE=(commandline -cp) %s)'", cmd, bin)
+		},
+		fileFn: func(cmd string) string {
+			return filepath.Join(opt.user.HomeDir(), dir, "completions", fmt.Sprintf("%s.fish", cmd))
+		},
+	}
+}
+
+// PowerShell creates a new Shell that writes a completion script for the
+// command, which is loaded from the pwsh profile. The completions are only
+// installed if the pwsh config directory exists.
+func PowerShell(options ...ShellOption) *Shell {
+	opt := new(shell)
+	for _, option := range options {
+		option(opt)
+	}
+
+	dir := filepath.Join(".config", "powershell")
+
+	var files []string
+	if path, ok := filePath(opt.fileSystem, opt.user, dir); ok {
+		files = append(files, filepath.Join(path, "Microsoft.PowerShell_profile.ps1"))
+	}
+
+	fileFn := func(cmd string) string {
+		return filepath.Join(opt.user.HomeDir(), dir, "completions", fmt.Sprintf("%s.ps1", cmd))
+	}
+
+	return &Shell{
+		fsys:  opt.fileSystem,
+		files: files,
+		cmdFn: func(cmd, bin string) string {
+			return fmt.Sprintf(powerShellCompletion, cmd, bin)
+		},
+		fileFn: fileFn,
+		setupFn: func(cmd string) string {
+			return fmt.Sprintf(". '%s'", fileFn(cmd))
+		},
+	}
+}
+
+const powerShellCompletion = `Register-ArgumentCompleter -Native -CommandName '%[1]s' -ScriptBlock {
+    param($wordToComplete, $commandAst, $cursorPosition)
+
+    $line = $commandAst.Extent.Text
+    $offset = $cursorPosition - $commandAst.Extent.StartOffset
+    if ($offset -lt $line.Length) {
+        $line = $line.Substring(0, $offset)
+    }
+    if ($wordToComplete -eq '' -and -not $line.EndsWith(' ')) {
+        $line += ' '
+    }
+
+    $env:COMP_LINE = $line
+    $env:COMP_DESCRIBE = '1'
+    $candidates = & '%[2]s' 2>$null
+    Remove-Item Env:COMP_LINE, Env:COMP_DESCRIBE
+
+    foreach ($candidate in $candidates) {
+        $value, $description = $candidate -split '(?<!\\):', 2
+        $value = $value -replace '\\:', ':'
+        if (-not $description) {
+            $description = $value
+        }
+        [System.Management.Automation.CompletionResult]::new($value, $value, 'ParameterValue', $description)
+    }
+}`
+
 func filePath(fsys fsys.FileSystem, user User, file string) (string, bool) {
 	path := filepath.Join(user.HomeDir(), file)
 	return path, path != "" && fsys.Exists(path)
@@ -181,7 +385,7 @@ func fileContains(fsys fsys.FileSystem, name, pattern string) bool {
 }
 
 func appendToFile(fsys fsys.FileSystem, name, content string) error {
-	f, err := fsys.OpenFile(name, os.O_RDWR|os.O_APPEND, 0755)
+	f, err := fsys.OpenFile(name, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0755)
 	if err != nil {
 		return err
 	}
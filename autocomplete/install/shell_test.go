@@ -1,6 +1,12 @@
 package install
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -39,16 +45,231 @@ func TestZsh(t *testing.T) {
 		fs := NewMockFileSystem(ctrl)
 		u := NewMockUser(ctrl)
 
-		u.EXPECT().HomeDir().Return("/home/test")
+		u.EXPECT().HomeDir().Return("/home/test").Times(2)
+
+		gomock.InOrder(
+			fs.EXPECT().Exists("/home/test/.zshrc").Return(true),
+		)
+
+		shell := Zsh(OptionFileSystem(fs), OptionUser(u))
+		cmd := shell.cmdFn("xxx", "file.bin")
+		if expected, actual := "#compdef xxx\n", cmd; !strings.HasPrefix(actual, expected) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := `COMP_LINE="${words[1,CURRENT]}" COMP_DESCRIBE=1 file.bin`, cmd; !strings.Contains(actual, expected) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := "/home/test/.zsh/completions/_xxx", shell.fileFn("xxx"); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("install", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		fs := NewMockDirFileSystem(ctrl)
+		file := NewMockFile(ctrl)
+		rc := NewMockFile(ctrl)
+		u := NewMockUser(ctrl)
+
+		u.EXPECT().HomeDir().Return("/home/test").Times(2)
+
+		setup := "(( ${fpath[(Ie)/home/test/.zsh/completions]} )) || fpath=(/home/test/.zsh/completions $fpath)"
+
+		gomock.InOrder(
+			fs.EXPECT().Exists("/home/test/.zshrc").Return(true),
+			fs.EXPECT().Exists("/home/test/.zsh/completions/_xxx").Return(false),
+			fs.EXPECT().MkdirAll("/home/test/.zsh/completions").Return(nil),
+			fs.EXPECT().Create("/home/test/.zsh/completions/_xxx").Return(file, nil),
+			file.EXPECT().Write(gomock.Any()).Return(0, nil),
+			fs.EXPECT().Open("/home/test/.zshrc").Return(nil, errors.New("not found")),
+			fs.EXPECT().OpenFile("/home/test/.zshrc", os.O_RDWR|os.O_APPEND|os.O_CREATE, os.FileMode(0755)).Return(rc, nil),
+			rc.EXPECT().Write([]byte(fmt.Sprintf("\n%s\n", setup))).Return(0, nil),
+			rc.EXPECT().Close().Return(nil),
+			file.EXPECT().Close().Return(nil),
+		)
+
+		shell := Zsh(OptionFileSystem(fs), OptionUser(u))
+		err := shell.Install("xxx", "file.bin")
+		if expected, actual := true, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v, err: %v", expected, actual, err)
+		}
+	})
+
+	t.Run("uninstall legacy", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		fs := NewMockFileSystem(ctrl)
+		file := NewMockFile(ctrl)
+		backupFile := NewMockFile(ctrl)
+		tmpFile := NewMockFile(ctrl)
+		u := NewMockUser(ctrl)
+
+		u.EXPECT().HomeDir().Return("/home/test").Times(2)
+
+		legacyLine := "complete -o nospace -C file.bin xxx"
+		read := func(b []byte) {
+			copy(b, legacyLine)
+		}
 
 		gomock.InOrder(
 			fs.EXPECT().Exists("/home/test/.zshrc").Return(true),
+
+			// FileContains
+			fs.EXPECT().Open("/home/test/.zshrc").Return(file, nil),
+			file.EXPECT().Read(gomock.Any()).Return(len(legacyLine), io.EOF).Do(read),
+			file.EXPECT().Close(),
+
+			// CopyFile
+			fs.EXPECT().Open("/home/test/.zshrc").Return(file, nil),
+			fs.EXPECT().Create("/home/test/.zshrc.bck").Return(backupFile, nil),
+			file.EXPECT().Read(gomock.Any()).Return(len(legacyLine), io.EOF).Do(read),
+			backupFile.EXPECT().Write([]byte(legacyLine)).Return(len(legacyLine), nil),
+			backupFile.EXPECT().Close(),
+			file.EXPECT().Close(),
+
+			// RemoveContentFromTmpFile
+			fs.EXPECT().Open("/home/test/.zshrc").Return(file, nil),
+			file.EXPECT().Read(gomock.Any()).Return(len(legacyLine), io.EOF).Do(read),
+			file.EXPECT().Close(),
+
+			// CopyFile
+			fs.EXPECT().Open(gomock.Any()).Return(tmpFile, nil),
+			fs.EXPECT().Create("/home/test/.zshrc").Return(file, nil),
+			tmpFile.EXPECT().Read(gomock.Any()).Return(0, io.EOF),
+			file.EXPECT().Close(),
+			tmpFile.EXPECT().Close(),
+
+			// Remove
+			fs.EXPECT().Remove("/home/test/.zshrc.bck").Return(nil),
+
+			// The completion file doesn't exist
+			fs.EXPECT().Open("/home/test/.zsh/completions/_xxx").Return(nil, errors.New("not found")),
 		)
 
 		shell := Zsh(OptionFileSystem(fs), OptionUser(u))
+		err := shell.Uninstall("xxx", "file.bin")
+		if expected, actual := true, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v, err: %v", expected, actual, err)
+		}
+	})
+}
+
+func TestFish(t *testing.T) {
+	t.Run("new", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		fs := NewMockFileSystem(ctrl)
+		u := NewMockUser(ctrl)
+
+		u.EXPECT().HomeDir().Return("/home/test").Times(2)
+
+		fs.EXPECT().Exists("/home/test/.config/fish").Return(true)
+
+		shell := Fish(OptionFileSystem(fs), OptionUser(u))
 		cmd := shell.cmdFn("xxx", "file.bin")
-		if expected, actual := "complete -o nospace -C file.bin xxx", cmd; expected != actual {
+		if expected, actual := "complete -c xxx -f -a '(env COMP_LINE=(commandline -cp) file.bin)'", cmd; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := "/home/test/.config/fish/completions/xxx.fish", shell.fileFn("xxx"); expected != actual {
 			t.Errorf("expected: %v, actual: %v", expected, actual)
 		}
 	})
+
+	t.Run("install", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		fs := NewMockDirFileSystem(ctrl)
+		file := NewMockFile(ctrl)
+		u := NewMockUser(ctrl)
+
+		u.EXPECT().HomeDir().Return("/home/test").Times(2)
+
+		gomock.InOrder(
+			fs.EXPECT().Exists("/home/test/.config/fish").Return(true),
+			fs.EXPECT().Exists("/home/test/.config/fish/completions/xxx.fish").Return(false),
+			fs.EXPECT().MkdirAll("/home/test/.config/fish/completions").Return(nil),
+			fs.EXPECT().Create("/home/test/.config/fish/completions/xxx.fish").Return(file, nil),
+			file.EXPECT().Write([]byte("complete -c xxx -f -a '(env COMP_LINE=(commandline -cp) file.bin)'\n")).Return(0, nil),
+			file.EXPECT().Close().Return(nil),
+		)
+
+		shell := Fish(OptionFileSystem(fs), OptionUser(u))
+		err := shell.Install("xxx", "file.bin")
+		if expected, actual := true, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v, err: %v", expected, actual, err)
+		}
+	})
+
+	t.Run("install without fish", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		fs := NewMockFileSystem(ctrl)
+		u := NewMockUser(ctrl)
+
+		u.EXPECT().HomeDir().Return("/home/test")
+
+		fs.EXPECT().Exists("/home/test/.config/fish").Return(false)
+
+		shell := Fish(OptionFileSystem(fs), OptionUser(u))
+		err := shell.Install("xxx", "file.bin")
+		if expected, actual := true, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v, err: %v", expected, actual, err)
+		}
+	})
+}
+
+func TestPowerShell(t *testing.T) {
+	t.Run("new", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		fs := NewMockFileSystem(ctrl)
+		u := NewMockUser(ctrl)
+
+		u.EXPECT().HomeDir().Return("/home/test").Times(3)
+
+		fs.EXPECT().Exists("/home/test/.config/powershell").Return(true)
+
+		shell := PowerShell(OptionFileSystem(fs), OptionUser(u))
+		cmd := shell.cmdFn("xxx", "file.bin")
+		if expected, actual := "Register-ArgumentCompleter -Native -CommandName 'xxx' -ScriptBlock {", cmd; !strings.HasPrefix(actual, expected) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := "$candidates = & 'file.bin' 2>$null", cmd; !strings.Contains(actual, expected) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := []string{"/home/test/.config/powershell/Microsoft.PowerShell_profile.ps1"}, shell.files; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := "/home/test/.config/powershell/completions/xxx.ps1", shell.fileFn("xxx"); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := ". '/home/test/.config/powershell/completions/xxx.ps1'", shell.setupFn("xxx"); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("install without pwsh", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		fs := NewMockFileSystem(ctrl)
+		u := NewMockUser(ctrl)
+
+		u.EXPECT().HomeDir().Return("/home/test")
+
+		fs.EXPECT().Exists("/home/test/.config/powershell").Return(false)
+
+		shell := PowerShell(OptionFileSystem(fs), OptionUser(u))
+		err := shell.Install("xxx", "file.bin")
+		if expected, actual := true, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v, err: %v", expected, actual, err)
+		}
+	})
 }
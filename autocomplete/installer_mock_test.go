@@ -85,6 +85,20 @@ func (m *MockGroup) EXPECT() *MockGroupMockRecorder {
 	return m.recorder
 }
 
+// Hidden mocks base method
+func (m *MockGroup) Hidden(arg0 string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Hidden", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Hidden indicates an expected call of Hidden
+func (mr *MockGroupMockRecorder) Hidden(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Hidden", reflect.TypeOf((*MockGroup)(nil).Hidden), arg0)
+}
+
 // WalkPrefix mocks base method
 func (m *MockGroup) WalkPrefix(arg0 string, arg1 radix.WalkFn) {
 	m.ctrl.T.Helper()
@@ -133,3 +147,17 @@ func (mr *MockCommandMockRecorder) FlagSet() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FlagSet", reflect.TypeOf((*MockCommand)(nil).FlagSet))
 }
+
+// Synopsis mocks base method
+func (m *MockCommand) Synopsis() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Synopsis")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Synopsis indicates an expected call of Synopsis
+func (mr *MockCommandMockRecorder) Synopsis() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Synopsis", reflect.TypeOf((*MockCommand)(nil).Synopsis))
+}
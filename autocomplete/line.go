@@ -6,7 +6,17 @@ import "os"
 // line.
 const EnvComplete = "COMP_LINE"
 
+// EnvDescribe is used for the AutoComplete for requesting that the candidates
+// are written along with their descriptions.
+const EnvDescribe = "COMP_DESCRIBE"
+
 // TerminalLine returns the current terminal line.
 var TerminalLine = func() string {
 	return os.Getenv(EnvComplete)
 }
+
+// Describe returns if the candidates should be written along with their
+// descriptions.
+var Describe = func() bool {
+	return os.Getenv(EnvDescribe) != ""
+}
@@ -3,17 +3,25 @@ package clui
 import (
 	"flag"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
 	"strings"
 
 	"github.com/pkg/errors"
+	"github.com/spoke-d/clui/argset"
 	"github.com/spoke-d/clui/autocomplete"
+	"github.com/spoke-d/clui/autocomplete/args"
 	"github.com/spoke-d/clui/autocomplete/fsys"
 	"github.com/spoke-d/clui/autocomplete/install"
 	"github.com/spoke-d/clui/commands"
+	"github.com/spoke-d/clui/config"
+	"github.com/spoke-d/clui/docs"
 	"github.com/spoke-d/clui/flagset"
 	"github.com/spoke-d/clui/group"
 	"github.com/spoke-d/clui/help"
+	"github.com/spoke-d/clui/man"
+	"github.com/spoke-d/clui/plugin"
 	"github.com/spoke-d/clui/ui"
 	task "github.com/spoke-d/task/group"
 )
@@ -42,6 +50,13 @@ type UI interface {
 	Error(string)
 }
 
+// OutputFormatter is a UI that can render the output in other formats than
+// text, such as JSON.
+type OutputFormatter interface {
+	// SetOutputFormat sets the format that Output renders the data with.
+	SetOutputFormat(ui.OutputFormat)
+}
+
 // Command is a runnable sub-command of CLI.
 type Command interface {
 
@@ -71,10 +86,32 @@ type Command interface {
 	// finished.
 	//
 	// There are a handful of special exit codes that can return documented
-	// behavioral changes.
+	// behavioral changes. Returning an ExitCoder error from the group will
+	// cause the CLI to exit with that code.
 	Run(*task.Group)
 }
 
+// ExternalCommand is a Command that is run outside of the CLI, such as a
+// plugin. External commands parse their own flags, so all the arguments and
+// flags after the command, including global flags, are passed to Init in the
+// order they were given.
+type ExternalCommand interface {
+	Command
+
+	// External returns if the command is external.
+	External() bool
+}
+
+// PositionalCommand is a Command that describes its positional arguments. The
+// arguments are checked before the command is initialized, and are used for
+// the usage line and for autocomplete.
+type PositionalCommand interface {
+	Command
+
+	// ArgSet returns the specification of the positional arguments.
+	ArgSet() *argset.ArgSet
+}
+
 // AutoCompleter is an interface to be implemented to perform the autocomplete
 // installation and un-installation with a CLI.
 //
@@ -85,7 +122,7 @@ type AutoCompleter interface {
 	// and print out the complete options.
 	// Returns success if the completion ran or if the cli matched
 	// any of the given flags, false otherwise
-	Complete(string) ([]string, bool)
+	Complete(string) ([]autocomplete.Candidate, bool)
 
 	// Install a command into the host using the Installer.
 	// Returns an error if there is an error whilst installing.
@@ -103,6 +140,14 @@ type CLIOptions interface {
 	SetAutoCompleter(AutoCompleter)
 	SetUI(UI)
 	SetFileSystem(fsys.FileSystem)
+	AppendMiddleware(Middleware)
+	SetPlugins(...plugin.FinderOption)
+	SetStdio(io.Reader, io.Writer, io.Writer)
+	SetEnv(func(string) (string, bool))
+	SetEnvPrefix(string)
+	SetUser(install.User)
+	SetManCommand(string)
+	SetDocsCommand(string)
 }
 
 // CLIOption captures a tweak that can be applied to the CLI.
@@ -113,6 +158,17 @@ type cli struct {
 	autoCompleter AutoCompleter
 	fileSystem    fsys.FileSystem
 	ui            UI
+	middleware    []Middleware
+	plugins       bool
+	pluginOptions []plugin.FinderOption
+	stdin         io.Reader
+	stdout        io.Writer
+	stderr        io.Writer
+	env           func(string) (string, bool)
+	envPrefix     string
+	user          install.User
+	manCommand    string
+	docsCommand   string
 }
 
 func (s *cli) SetHelpFunc(p help.Func) {
@@ -134,22 +190,102 @@ func (s *cli) SetFileSystem(p fsys.FileSystem) {
 	s.fileSystem = p
 }
 
-func (s *cli) AutoCompleter(group *group.Group, fs fsys.FileSystem) AutoCompleter {
+func (s *cli) AppendMiddleware(p Middleware) {
+	s.middleware = append(s.middleware, p)
+}
+
+func (s *cli) SetPlugins(p ...plugin.FinderOption) {
+	s.plugins = true
+	s.pluginOptions = p
+}
+
+func (s *cli) PluginFinder(name string) *plugin.Finder {
+	if !s.plugins {
+		return nil
+	}
+	// The plugins use the env and stdio of the cli, unless the options say
+	// otherwise.
+	options := []plugin.FinderOption{
+		plugin.OptionStdio(s.Stdio()),
+	}
+	if s.env != nil {
+		options = append(options, plugin.OptionEnv(s.env))
+	}
+	return plugin.NewFinder(name, append(options, s.pluginOptions...)...)
+}
+
+func (s *cli) SetStdio(stdin io.Reader, stdout, stderr io.Writer) {
+	s.stdin = stdin
+	s.stdout = stdout
+	s.stderr = stderr
+}
+
+func (s *cli) Stdio() (io.Reader, io.Writer, io.Writer) {
+	stdin, stdout, stderr := s.stdin, s.stdout, s.stderr
+	if stdin == nil {
+		stdin = os.Stdin
+	}
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+	if stderr == nil {
+		stderr = os.Stderr
+	}
+	return stdin, stdout, stderr
+}
+
+func (s *cli) SetEnv(p func(string) (string, bool)) {
+	s.env = p
+}
+
+func (s *cli) SetEnvPrefix(p string) {
+	s.envPrefix = p
+}
+
+func (s *cli) SetUser(p install.User) {
+	s.user = p
+}
+
+func (s *cli) SetManCommand(p string) {
+	s.manCommand = p
+}
+
+func (s *cli) SetDocsCommand(p string) {
+	s.docsCommand = p
+}
+
+func (s *cli) User() (install.User, error) {
+	if s.user == nil {
+		return install.CurrentUser()
+	}
+	return s.user, nil
+}
+
+func (s *cli) AutoCompleter(group *group.Group, fs fsys.FileSystem, globals *GlobalFlags) AutoCompleter {
 	if s.autoCompleter == nil {
-		user, err := install.CurrentUser()
+		user, err := s.User()
 		if err != nil {
 			return nil
 		}
 		installer, err := install.New(
 			install.OptionShell(install.Bash(install.OptionUser(user), install.OptionFileSystem(fs))),
 			install.OptionShell(install.Zsh(install.OptionUser(user), install.OptionFileSystem(fs))),
+			install.OptionShell(install.Fish(install.OptionUser(user), install.OptionFileSystem(fs))),
+			install.OptionShell(install.PowerShell(install.OptionUser(user), install.OptionFileSystem(fs))),
 		)
 		if err != nil {
 			return nil
 		}
-		return autocomplete.New(autocomplete.OptionGroup(group),
+		options := []autocomplete.AutoCompleteOption{
+			autocomplete.OptionGroup(group),
 			autocomplete.OptionInstaller(installer),
-		)
+			autocomplete.OptionGlobalFlags(globals.FlagSet()),
+		}
+		// Predict files through the filesystem of the cli, if it's able to.
+		if files, ok := fs.(args.FileSystem); ok {
+			options = append(options, autocomplete.OptionFileSystem(files))
+		}
+		return autocomplete.New(options...)
 	}
 	return s.autoCompleter
 }
@@ -160,7 +296,7 @@ func (s *cli) SetUI(p UI) {
 
 func (s *cli) UI() UI {
 	if s.ui == nil {
-		return ui.NewBasicUI(os.Stdin, os.Stdout, os.Stderr)
+		return ui.NewBasicUI(s.Stdio())
 	}
 	return s.ui
 }
@@ -194,9 +330,101 @@ func OptionFileSystem(i fsys.FileSystem) CLIOption {
 	}
 }
 
+// OptionMiddleware allows the appending of a Middleware option to configure
+// the cli. Middleware is called in the order it was added, the first being the
+// outer most.
+func OptionMiddleware(i Middleware) CLIOption {
+	return func(opt CLIOptions) {
+		opt.AppendMiddleware(i)
+	}
+}
+
+// OptionPlugins allows the enabling of plugins to configure the cli. Plugins
+// that are found are added as commands, unless a command with the same name
+// already exists. The plugins are found for the name of the cli, with the
+// env and stdio of the cli, which the finder options can override.
+func OptionPlugins(i ...plugin.FinderOption) CLIOption {
+	return func(opt CLIOptions) {
+		opt.SetPlugins(i...)
+	}
+}
+
+// OptionStdio allows the setting of the standard input and outputs that the
+// cli uses, when no UI is set.
+func OptionStdio(stdin io.Reader, stdout, stderr io.Writer) CLIOption {
+	return func(opt CLIOptions) {
+		opt.SetStdio(stdin, stdout, stderr)
+	}
+}
+
+// OptionEnv allows the setting of the function used to look up environment
+// variables, which is os.LookupEnv by default.
+func OptionEnv(i func(string) (string, bool)) CLIOption {
+	return func(opt CLIOptions) {
+		opt.SetEnv(i)
+	}
+}
+
+// OptionEnvPrefix allows the setting of the prefix of the environment
+// variables that are bound to the command flags, so a prefix of "mycli" binds
+// the "user" flag to "MYCLI_USER". There is no prefix by default, which binds
+// the flags to the upper-cased flag name.
+func OptionEnvPrefix(i string) CLIOption {
+	return func(opt CLIOptions) {
+		opt.SetEnvPrefix(i)
+	}
+}
+
+// OptionUser allows the setting of the user that autocomplete is installed
+// for, which is the current operating system user by default.
+func OptionUser(i install.User) CLIOption {
+	return func(opt CLIOptions) {
+		opt.SetUser(i)
+	}
+}
+
+// OptionManCommand allows the adding of a hidden command, with the given key,
+// that writes the man pages for the cli to a directory.
+func OptionManCommand(key string) CLIOption {
+	return func(opt CLIOptions) {
+		opt.SetManCommand(key)
+	}
+}
+
+// OptionDocsCommand allows the adding of a hidden command, with the given key,
+// that writes the Markdown or HTML reference pages for the cli to a directory.
+func OptionDocsCommand(key string) CLIOption {
+	return func(opt CLIOptions) {
+		opt.SetDocsCommand(key)
+	}
+}
+
 // CommandFn defines a function for constructing a command.
 type CommandFn func(UI) Command
 
+// CommandOption captures a tweak that can be applied to a command when it's
+// added to the CLI.
+type CommandOption = group.CommandOption
+
+// OptionAliases allows the setting of alternative names for a command. Each
+// alias replaces the last name of the command key, so an alias of "ls" for
+// "config list" can be called as "config ls".
+func OptionAliases(aliases ...string) CommandOption {
+	return group.OptionAliases(aliases...)
+}
+
+// OptionHidden allows the hiding of a command from help, autocomplete and any
+// other listings. Hidden commands can still be run.
+func OptionHidden() CommandOption {
+	return group.OptionHidden()
+}
+
+// OptionDeprecated allows the marking of a command as deprecated. Running the
+// command will warn the operator to use the replacement command instead.
+func OptionDeprecated(replacement string) CommandOption {
+	return group.OptionDeprecated(replacement)
+}
+
 // CLI contains the state necessary to run commands and parse the command line
 // arguments
 //
@@ -225,6 +453,12 @@ type CLI struct {
 
 	commands     *group.Group
 	commandFlags []string
+	globalFlags  *GlobalFlags
+	middleware   []Middleware
+	pluginFinder *plugin.Finder
+	fileSystem   fsys.FileSystem
+	env          func(string) (string, bool)
+	envPrefix    string
 
 	args *GlobalArgs
 }
@@ -240,6 +474,8 @@ func New(name, version, header string, options ...CLIOption) *CLI {
 		return commands.NewText(s, TemplatePlaceHolder)
 	}))
 
+	globals := NewGlobalFlags()
+
 	cli := &CLI{
 		name:          name,
 		version:       version,
@@ -247,22 +483,86 @@ func New(name, version, header string, options ...CLIOption) *CLI {
 		ui:            opt.UI(),
 		helpFunc:      opt.HelpFunc(name),
 		commands:      store,
-		autoCompleter: opt.AutoCompleter(store, opt.fileSystem),
+		globalFlags:   globals,
+		autoCompleter: opt.AutoCompleter(store, opt.fileSystem, globals),
+		middleware:    opt.middleware,
+		pluginFinder:  opt.PluginFinder(name),
+		fileSystem:    opt.fileSystem,
+		env:           opt.env,
+		envPrefix:     opt.envPrefix,
 	}
 
-	store.Add("shell", commands.NewShell(runnable(cli), store))
+	store.Add("shell", commands.NewShell(runnable(cli), store,
+		commands.OptionStdio(opt.Stdio()),
+	))
+	if opt.manCommand != "" {
+		store.Add(opt.manCommand, man.NewCommand(func() ([]man.Page, error) {
+			return cli.ManPages()
+		}), group.OptionHidden())
+	}
+	if opt.docsCommand != "" {
+		store.Add(opt.docsCommand, docs.NewCommand(func(format docs.Format) ([]docs.Page, error) {
+			return cli.Docs(docs.OptionFormat(format))
+		}), group.OptionHidden())
+	}
 	return cli
 }
 
 // Add inserts a new command to the CLI.
-func (c *CLI) Add(key string, cmdFn CommandFn) error {
-	return c.commands.Add(key, cmdFn(c.ui))
+func (c *CLI) Add(key string, cmdFn CommandFn, options ...CommandOption) error {
+	return c.commands.Add(key, cmdFn(c.ui), options...)
+}
+
+// GlobalFlags returns the FlagSet for registering flags that are available to
+// every command. The parsed flags are passed to each command via the
+// CommandContext.
+func (c *CLI) GlobalFlags() *flagset.FlagSet {
+	return c.globalFlags.FlagSet()
+}
+
+// ManPages generates the man pages for every command, along with a top level
+// page for the cli.
+func (c *CLI) ManPages(options ...man.ManOption) ([]man.Page, error) {
+	if err := c.commands.Process(); err != nil {
+		return nil, err
+	}
+	return man.Generate(c.name, c.commands, append([]man.ManOption{
+		man.OptionVersion(c.version),
+		man.OptionDescription(c.header),
+		man.OptionGlobalFlags(c.globalFlags.Help()),
+	}, options...)...)
+}
+
+// Docs generates the reference pages for every command of the cli, along
+// with a top level page. The pages are written as Markdown by default.
+func (c *CLI) Docs(options ...docs.DocsOption) ([]docs.Page, error) {
+	if err := c.commands.Process(); err != nil {
+		return nil, err
+	}
+	return docs.Generate(c.name, c.commands, append([]docs.DocsOption{
+		docs.OptionDescription(c.header),
+		docs.OptionGlobalFlags(c.globalFlags.Help()),
+		docs.OptionChildren(func(commands *group.Group, key string) (map[string]group.Command, error) {
+			children, err := FindChildren(commands, key, false)
+			if err != nil {
+				return nil, err
+			}
+			shims := make(map[string]group.Command, len(children))
+			for k, v := range children {
+				shims[k] = v
+			}
+			return shims, nil
+		}),
+	}, options...)...)
 }
 
 // Run runs the actual CLI bases on the arguments given.
 func (c *CLI) Run(args []string) (Errno, error) {
-	c.args = NewGlobalArgs(c.commands)
+	c.args = NewGlobalArgs(c.commands, OptionGlobalFlags(c.globalFlags))
 
+	if err := c.addPlugins(); err != nil {
+		return EPerm, err
+	}
 	if err := c.commands.Process(); err != nil {
 		return EPerm, err
 	}
@@ -276,9 +576,53 @@ func (c *CLI) Run(args []string) (Errno, error) {
 	// -help or -version or other flags and we want to show completions
 	// and not actually write the help or version.
 	// TODO: Get this from options
-	if commands, ok := c.autoCompleter.Complete(autocomplete.TerminalLine()); ok {
+	if candidates, ok := c.autoCompleter.Complete(c.terminalLine()); ok {
 		template := ui.NewTemplate(TemplateComplete)
-		return EOK, c.ui.Output(template, commands)
+		return EOK, c.ui.Output(template, c.completions(candidates))
+	}
+
+	// Flags of the command take precedence over global flags of the same
+	// name, when they're passed after the command.
+	var (
+		flags *flagset.FlagSet
+		cfg   *config.Config
+	)
+	if command, ok := c.commands.Get(c.args.SubCommand()); ok && isExternal(command) {
+		// External commands parse their own flags, so every flag passed after
+		// the command is left for it.
+		if err := c.args.Shadow(c.globalFlags.all()); err != nil {
+			return EPerm, err
+		}
+	} else if ok {
+		// The config isn't needed for the help, so that a bad config doesn't
+		// prevent the help from being shown.
+		var err error
+		if !c.args.Help() {
+			if cfg, err = c.loadConfig(); err != nil {
+				return EPerm, err
+			}
+		}
+		flags = command.FlagSet()
+		if shadowed := c.globalFlags.Shadowed(flags); len(shadowed) > 0 {
+			if err := c.args.Shadow(shadowed); err != nil {
+				return EPerm, err
+			}
+			// The config path, or the help flag, may have been passed to the
+			// command instead.
+			if _, ok := shadowed[flagConfig]; (ok || cfg == nil) && !c.args.Help() {
+				if cfg, err = c.loadConfig(); err != nil {
+					return EPerm, err
+				}
+			}
+		}
+	}
+
+	format, err := ui.ParseOutputFormat(c.args.OutputFormat())
+	if err != nil {
+		return EPerm, err
+	}
+	if formatter, ok := c.ui.(OutputFormatter); ok {
+		formatter.SetOutputFormat(format)
 	}
 
 	// Just show the version and exit if instructed.
@@ -315,47 +659,227 @@ func (c *CLI) Run(args []string) (Errno, error) {
 		return c.writeHelp(c.subCommandParent())
 	}
 
-	// Run the command
-	if err := command.FlagSet().Parse(c.args.SubCommandFlags()); err != nil {
-		return c.commandHelp(command, err.Error())
-	}
-
 	// If we've been instructed to just print the help, then print help
 	if c.args.Help() {
 		return c.commandHelp(command, "")
 	}
 
+	var (
+		constraintErr error
+		argSet        *argset.ArgSet
+	)
+
+	// External commands parse their own flags, so pass everything through.
+	arguments := c.args.SubCommandRawArgs()
+	if !isExternal(command) {
+		flags.SetConfig(cfg.Values(c.args.SubCommand()))
+		flags.SetEnvPrefix(c.envPrefix)
+		if c.env != nil {
+			flags.SetEnv(c.env)
+		}
+		if c.fileSystem != nil {
+			flags.SetReadFile(c.readFile)
+		}
+		if err := flags.Parse(arguments); err != nil {
+			return c.commandHelp(command, err.Error())
+		}
+		arguments = flags.Args()
+		constraintErr = flags.Validate()
+		if positional, ok := command.(PositionalCommand); ok {
+			argSet = positional.ArgSet()
+		}
+	}
+
 	// If there is an invalid flag, then error
 	if len(c.commandFlags) > 0 {
 		return c.commandHelp(command, "")
 	}
 
+	// Required and grouped flags are checked after the help, so that the help
+	// can still be shown when they're missing.
+	if constraintErr != nil {
+		return c.commandHelp(command, constraintErr.Error())
+	}
+
+	// Check the positional arguments, now that the flags have been removed.
+	if argSet != nil {
+		if err := argSet.Parse(arguments); err != nil {
+			return c.commandHelp(command, err.Error())
+		}
+	}
+
+	// Warn the operator if the command is going away.
+	if replacement, ok := c.commands.Deprecated(c.args.SubCommand()); ok {
+		if err := c.writeDeprecated(replacement); err != nil {
+			return EPerm, errors.WithStack(err)
+		}
+	}
+
 	// Remove the flags, those are handled by the flagset.
 	ctx := commands.CommandContext{
-		Debug:   c.args.Debug(),
-		DevMode: c.args.DevMode(),
-	}
-	if err := command.Init(c.args.SubCommandArgs(), ctx); err != nil {
-		return c.commandHelp(command, err.Error())
+		Debug:       c.args.Debug(),
+		DevMode:     c.args.DevMode(),
+		GlobalFlags: c.args.GlobalFlags(),
 	}
 
 	// Create a new group context to run.
 	g := task.NewGroup()
 
+	handler := chain(c.handle, c.middleware...)
+	code, err := handler(Invocation{
+		Key:     c.args.SubCommand(),
+		Args:    arguments,
+		Context: ctx,
+		Command: command,
+	}, g)
+
+	switch {
+	case err == nil:
+		return code, nil
+	case err == commands.ErrShowHelp:
+		return c.commandHelp(command, "")
+	}
+
+	// Errors with explicit exit codes take precedence over the code from the
+	// handler. An error should never be reported as a success though.
+	if errno, ok := exitCode(err); ok {
+		code = errno
+	} else if code == EOK {
+		code = EPerm
+	}
+
+	// Only show the usage of a command if it was used incorrectly, otherwise
+	// it's a runtime error.
+	if commands.IsUsageError(err) {
+		if _, err := c.commandHelp(command, err.Error()); err != nil {
+			return EPerm, err
+		}
+		return code, nil
+	}
+	if reported(err) {
+		return code, nil
+	}
+	if err := c.writeError(err); err != nil {
+		return EPerm, err
+	}
+	return code, nil
+}
+
+// handle initialises and runs the command. This is the inner most handler
+// that all the middleware wraps.
+func (c *CLI) handle(inv Invocation, g *task.Group) (Errno, error) {
+	if err := inv.Command.Init(inv.Args, inv.Context); err != nil {
+		return EPerm, err
+	}
+
 	// Subscribe all the actions to the group.
 	task.Block(g)
-	command.Run(g)
+	inv.Command.Run(g)
 	task.Interrupt(g)
 
 	// Run the group
-	switch err := g.Run(); err {
-	case commands.ErrShowHelp:
-		return c.commandHelp(command, "")
-	case nil:
-		return EOK, nil
-	default:
-		return c.commandHelp(command, err.Error())
+	if err := g.Run(); err != nil {
+		return EPerm, err
+	}
+	return EOK, nil
+}
+
+// loadConfig loads the config file passed with the config flag, otherwise the
+// config file for the CLI from the user config directory, if there is one.
+func (c *CLI) loadConfig() (*config.Config, error) {
+	path := c.args.ConfigPath()
+	if path == "" {
+		var (
+			ok     bool
+			exists func(string) bool
+		)
+		if c.fileSystem != nil {
+			exists = c.fileSystem.Exists
+		}
+		if path, ok = config.DefaultPath(c.name, c.env, exists); !ok {
+			return config.New(), nil
+		}
+	}
+	if c.fileSystem == nil {
+		return config.Load(path)
+	}
+
+	file, err := c.fileSystem.Open(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer file.Close()
+
+	return config.Read(path, file)
+}
+
+// readFile reads the named file from the file system of the CLI.
+func (c *CLI) readFile(name string) ([]byte, error) {
+	file, err := c.fileSystem.Open(name)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer file.Close()
+
+	return ioutil.ReadAll(file)
+}
+
+// terminalLine returns the line that is being completed, if any.
+func (c *CLI) terminalLine() string {
+	if c.env == nil {
+		return autocomplete.TerminalLine()
+	}
+	line, _ := c.env(autocomplete.EnvComplete)
+	return line
+}
+
+// completions returns the values of the candidates, along with their
+// descriptions if the shell asked for them.
+func (c *CLI) completions(candidates []autocomplete.Candidate) []string {
+	describe := autocomplete.Describe()
+	if c.env != nil {
+		value, _ := c.env(autocomplete.EnvDescribe)
+		describe = value != ""
+	}
+
+	res := make([]string, len(candidates))
+	for i, candidate := range candidates {
+		if describe {
+			res[i] = candidate.Describe()
+		} else {
+			res[i] = candidate.Value
+		}
 	}
+	return res
+}
+
+// addPlugins adds any plugins that can be found as commands. Commands that
+// already exist take precedence over plugins.
+func (c *CLI) addPlugins() error {
+	if c.pluginFinder == nil {
+		return nil
+	}
+	for key, cmd := range c.pluginFinder.Find() {
+		if _, ok := c.commands.Get(key); ok {
+			continue
+		}
+		if err := c.commands.Add(key, cmd); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+// describePlugins describes any plugins within the commands in parallel,
+// rather than one at a time as the help lists them.
+func describePlugins(commands map[string]Command) {
+	var plugins []*plugin.Command
+	for _, cmd := range commands {
+		if p, ok := cmd.(*plugin.Command); ok {
+			plugins = append(plugins, p)
+		}
+	}
+	plugin.Describe(plugins...)
 }
 
 // subCommandParent returns the parent of this subCommand, if there is one.
@@ -384,6 +908,8 @@ func (c *CLI) writeHelp(command string) (Errno, error) {
 		return EPerm, errors.WithStack(err)
 	}
 
+	describePlugins(children)
+
 	shims := make(map[string]help.Command, len(children))
 	for k, v := range children {
 		shims[k] = v
@@ -408,6 +934,7 @@ func (c *CLI) writeHelp(command string) (Errno, error) {
 		help.OptionHint(hint),
 		help.OptionColor(!c.args.RequiresNoColor()),
 		help.OptionTemplate(help.BasicHelpTemplate),
+		help.OptionGlobalFlags(c.globalFlags.Help()),
 		help.OptionShowHelp(hint == ""),
 	)
 	if err != nil {
@@ -426,6 +953,8 @@ func (c *CLI) commandHelp(command Command, operatorErr string) (Errno, error) {
 		return EPerm, errors.WithStack(err)
 	}
 
+	describePlugins(children)
+
 	shims := make(map[string]help.Command, len(children))
 	for k, v := range children {
 		shims[k] = v
@@ -441,11 +970,26 @@ func (c *CLI) commandHelp(command Command, operatorErr string) (Errno, error) {
 		header = c.header
 	}
 
-	flags, err := commandFlags(command.FlagSet())
+	flagSet := command.FlagSet()
+	flags, err := commandFlags(flagSet, false)
+	if err != nil {
+		return EPerm, errors.WithStack(err)
+	}
+	inherited, err := commandFlags(flagSet, true)
 	if err != nil {
 		return EPerm, errors.WithStack(err)
 	}
 
+	var args string
+	if positional, ok := command.(PositionalCommand); ok {
+		args = positional.ArgSet().Usage()
+	}
+
+	var aliases []string
+	for _, alias := range c.commands.Aliases(subCommand) {
+		aliases = append(aliases, fmt.Sprintf("%s %s", c.name, alias))
+	}
+
 	fn := help.BasicFunc(fmt.Sprintf("%s %s", c.name, subCommand))
 	res, err := fn(
 		help.OptionCommands(shims),
@@ -453,10 +997,14 @@ func (c *CLI) commandHelp(command Command, operatorErr string) (Errno, error) {
 		help.OptionHint(hint),
 		help.OptionColor(!c.args.RequiresNoColor()),
 		help.OptionTemplate(help.CommandHelpTemplate),
+		help.OptionGlobalFlags(c.globalFlags.Help()),
 		help.OptionHelp(command.Help()),
 		help.OptionFlags(flags),
+		help.OptionInheritedFlags(inherited),
 		help.OptionUsages(command.Usages()),
-		help.OptionErr(operatorErr),
+		help.OptionArgs(args),
+		help.OptionAliases(aliases),
+		help.OptionErr(strings.Replace(operatorErr, "\n", "\n    ", -1)),
 		help.OptionShowHelp(hint == "" && operatorErr == ""),
 	)
 	if err != nil {
@@ -480,25 +1028,81 @@ func (c *CLI) writeVersion(s string) (Errno, error) {
 	})
 }
 
-func commandFlags(flags *flagset.FlagSet) ([]string, error) {
+func (c *CLI) writeError(cause error) error {
+	var (
+		causes []string
+		stack  string
+	)
+	if c.args.Debug() {
+		causes = errorCauses(cause)
+		stack = errorStack(cause)
+	}
+
+	fn := help.BasicFunc(fmt.Sprintf("%s %s", c.name, c.args.SubCommand()))
+	res, err := fn(
+		help.OptionColor(!c.args.RequiresNoColor()),
+		help.OptionTemplate(help.ErrorTemplate),
+		help.OptionErr(cause.Error()),
+		help.OptionCauses(causes),
+		help.OptionStack(stack),
+	)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	c.ui.Error(strings.TrimSpace(res))
+	return nil
+}
+
+func (c *CLI) writeDeprecated(replacement string) error {
+	template := ui.NewTemplate(TemplateDeprecated)
+	res, err := template.Render(struct {
+		Name        string
+		Replacement string
+	}{
+		Name:        fmt.Sprintf("%s %s", c.name, c.args.SubCommand()),
+		Replacement: fmt.Sprintf("%s %s", c.name, replacement),
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	c.ui.Error(strings.TrimSpace(res))
+	return nil
+}
+
+// commandFlags renders the flags of a FlagSet for the help output. Inherited
+// flags are rendered separately from the flags of the command.
+func commandFlags(flags *flagset.FlagSet, inherited bool) ([]string, error) {
 	type flagType struct {
 		Name     string
+		Type     string
 		Usage    string
 		Defaults string
+		Choices  string
+		Required bool
 	}
 
 	template := ui.NewTemplate(TemplateFlags, ui.OptionName("flags"))
 	var allFlags []*flag.Flag
 	flags.VisitAll(func(f *flag.Flag) {
-		allFlags = append(allFlags, f)
+		if flags.Inherited(f.Name) == inherited {
+			allFlags = append(allFlags, f)
+		}
 	})
 
 	data := make([]string, len(allFlags))
 	for k, v := range allFlags {
+		name := fmt.Sprintf("--%s", v.Name)
+		if short := flags.Short(v.Name); short != "" {
+			name = fmt.Sprintf("-%s, %s", short, name)
+		}
+		choices, _ := flags.Choices(v.Name)
 		res, err := template.Render(flagType{
-			Name:     fmt.Sprintf("--%s", v.Name),
+			Name:     name,
+			Type:     flags.Type(v.Name),
+			Choices:  strings.Join(choices, ", "),
 			Usage:    v.Usage,
 			Defaults: v.DefValue,
+			Required: flags.Required(v.Name),
 		})
 		if err != nil {
 			return nil, errors.WithStack(err)
@@ -513,6 +1117,12 @@ type runner struct {
 	cli *CLI
 }
 
+// isExternal returns if the command is run outside of the CLI.
+func isExternal(command Command) bool {
+	ext, ok := command.(ExternalCommand)
+	return ok && ext.External()
+}
+
 func runnable(cli *CLI) runner {
 	return runner{
 		cli: cli,
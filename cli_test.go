@@ -0,0 +1,545 @@
+package clui
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spoke-d/clui/argset"
+	"github.com/spoke-d/clui/autocomplete/args"
+	"github.com/spoke-d/clui/autocomplete/fsys"
+	"github.com/spoke-d/clui/commands"
+	"github.com/spoke-d/clui/flagset"
+	"github.com/spoke-d/clui/ui"
+	"github.com/spoke-d/task/group"
+)
+
+func TestCommandFlags(t *testing.T) {
+	t.Parallel()
+
+	t.Run("short flag", func(t *testing.T) {
+		var buf bytes.Buffer
+		cli := New("cli", "1.0.0", "",
+			OptionStdio(nil, &buf, &buf),
+			OptionEnv(lookupEnv(nil)),
+			OptionAutoCompleter(nopAutoCompleter{}),
+		)
+		cli.Add("greet", greetCmdFn)
+
+		code, err := cli.Run([]string{"greet", "-n=fred"})
+		if expected, actual := true, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v, err: %v", expected, actual, err)
+		}
+		if expected, actual := EOK, code; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := "Hello fred!\n", buf.String(); expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+	})
+
+	t.Run("flag constraints", func(t *testing.T) {
+		var buf bytes.Buffer
+		cli := New("cli", "1.0.0", "",
+			OptionStdio(nil, &buf, &buf),
+			OptionEnv(lookupEnv(nil)),
+			OptionAutoCompleter(nopAutoCompleter{}),
+		)
+		cli.Add("login", func(UI) Command {
+			flagSet := flagset.New("login", flag.ContinueOnError)
+			flagSet.String("user", "", "User to login as")
+			flagSet.String("password", "", "Password of the user")
+			flagSet.String("server", "", "Server to login to")
+			flagSet.Bool("json", false, "Output as JSON")
+			flagSet.String("template", "", "Output with a template")
+			flagSet.MarkRequired("server")
+			flagSet.MarkRequiredTogether("user", "password")
+			flagSet.MarkExclusive("json", "template")
+			return &loginCmd{flagSet: flagSet}
+		})
+
+		code, _ := cli.Run([]string{"login", "--user=fred", "--json", "--template=x"})
+		if expected, actual := EPerm, code; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		for _, violation := range []string{
+			"required flag --server not set",
+			"flags --user, --password must be used together, missing --password",
+			"flags --json, --template can not be used together",
+		} {
+			if !strings.Contains(buf.String(), violation) {
+				t.Errorf("expected %q in output: %s", violation, buf.String())
+			}
+		}
+
+		buf.Reset()
+		cli.Run([]string{"login", "--help"})
+		if !strings.Contains(buf.String(), "--server <string>      Server to login to (required)") {
+			t.Errorf("expected required flag in output: %s", buf.String())
+		}
+	})
+
+	t.Run("interspersed flags", func(t *testing.T) {
+		var (
+			buf                bytes.Buffer
+			mode               string
+			recursive, verbose bool
+		)
+		cli := New("cli", "1.0.0", "",
+			OptionStdio(nil, &buf, &buf),
+			OptionEnv(lookupEnv(nil)),
+			OptionAutoCompleter(nopAutoCompleter{}),
+		)
+		cli.Add("copy", func(ui UI) Command {
+			cmd := copyCmdFn(ui).(*copyCmd)
+			cmd.flagSet.StringVar(&mode, "mode", "", "How to copy")
+			cmd.flagSet.BoolVarP(&recursive, "recursive", "r", false, "Copy directories")
+			cmd.flagSet.BoolVarP(&verbose, "verbose", "V", true, "Show progress")
+			return cmd
+		})
+
+		for _, args := range [][]string{
+			{"copy", "a.yaml", "b", "--mode", "fast", "-r", "--no-verbose"},
+			{"copy", "--mode", "fast", "a.yaml", "-rV", "b", "--no-verbose"},
+			{"copy", "-r", "a.yaml", "--mode=fast", "--verbose=false", "b"},
+		} {
+			buf.Reset()
+			_, err := cli.Run(args)
+			if expected, actual := "copy a.yaml to \"b\"\n", buf.String(); expected != actual {
+				t.Errorf("%v: expected: %q, actual: %q, err: %v", args, expected, actual, err)
+			}
+			if mode != "fast" || !recursive || verbose {
+				t.Errorf("%v: unexpected flags: mode=%q recursive=%v verbose=%v", args, mode, recursive, verbose)
+			}
+		}
+	})
+
+	t.Run("global flag clash", func(t *testing.T) {
+		var (
+			buf     bytes.Buffer
+			format  string
+			verbose bool
+		)
+		cli := New("cli", "1.0.0", "",
+			OptionStdio(nil, &buf, &buf),
+			OptionEnv(lookupEnv(nil)),
+			OptionAutoCompleter(nopAutoCompleter{}),
+		)
+		cli.Add("copy", func(ui UI) Command {
+			cmd := copyCmdFn(ui).(*copyCmd)
+			cmd.flagSet.StringVar(&format, "format", "yaml", "Format of the copy")
+			cmd.flagSet.BoolVarP(&verbose, "verbose", "v", false, "Show progress")
+			return cmd
+		})
+
+		_, err := cli.Run([]string{"copy", "--format", "csv", "a.yaml", "-v"})
+		if expected, actual := "copy a.yaml to \"\"\n", buf.String(); expected != actual {
+			t.Errorf("expected: %q, actual: %q, err: %v", expected, actual, err)
+		}
+		if expected, actual := "csv", format; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := true, verbose; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+
+		buf.Reset()
+		cli.Run([]string{"-v", "copy", "a.yaml"})
+		if expected, actual := "Client version: 1.0.0\n", buf.String(); expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+	})
+
+	t.Run("enum flags", func(t *testing.T) {
+		var buf bytes.Buffer
+		cli := New("cli", "1.0.0", "",
+			OptionStdio(nil, &buf, &buf),
+			OptionEnv(lookupEnv(nil)),
+			OptionAutoCompleter(nopAutoCompleter{}),
+		)
+		cli.Add("load", loadCmdFn)
+
+		cli.Run([]string{"load", "--help"})
+		if !strings.Contains(buf.String(), "How to load the file (one of: merge, replace)") {
+			t.Errorf("expected choices in output: %s", buf.String())
+		}
+
+		buf.Reset()
+		cli.Run([]string{"load", "--mode=append"})
+		if !strings.Contains(buf.String(), `invalid value "append" for flag -mode: must be one of merge, replace`) {
+			t.Errorf("expected invalid value in output: %s", buf.String())
+		}
+	})
+}
+
+func TestPositionalArguments(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	cli := New("cli", "1.0.0", "",
+		OptionStdio(nil, &buf, &buf),
+		OptionEnv(lookupEnv(nil)),
+		OptionAutoCompleter(nopAutoCompleter{}),
+	)
+	cli.Add("copy", copyCmdFn)
+
+	_, err := cli.Run([]string{"copy", "a.yaml"})
+	if expected, actual := "copy a.yaml to \"\"\n", buf.String(); expected != actual {
+		t.Errorf("expected: %q, actual: %q, err: %v", expected, actual, err)
+	}
+
+	buf.Reset()
+	_, err = cli.Run([]string{"copy", "--", "--format.yaml"})
+	if expected, actual := "copy --format.yaml to \"\"\n", buf.String(); expected != actual {
+		t.Errorf("expected: %q, actual: %q, err: %v", expected, actual, err)
+	}
+
+	buf.Reset()
+	cli.Run([]string{"copy"})
+	if !strings.Contains(buf.String(), "missing argument <src>") {
+		t.Errorf("expected missing argument in output: %s", buf.String())
+	}
+
+	buf.Reset()
+	cli.Run([]string{"copy", "a.yaml", "b", "c"})
+	if !strings.Contains(buf.String(), "too many arguments: c") {
+		t.Errorf("expected too many arguments in output: %s", buf.String())
+	}
+
+	buf.Reset()
+	cli.Run([]string{"copy", "--help"})
+	if !strings.Contains(buf.String(), "cli copy <src> [<dst>]\n") {
+		t.Errorf("expected usage line in output: %s", buf.String())
+	}
+}
+
+func TestAutoComplete(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "complete")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, path := range []string{
+		"config.yaml",
+		"notes.txt",
+		"conf/other.yaml",
+		"contrib/misc.txt",
+	} {
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	complete := func(line string, describe bool) string {
+		env := map[string]string{
+			"COMP_LINE": line,
+		}
+		if describe {
+			env["COMP_DESCRIBE"] = "1"
+		}
+
+		var buf bytes.Buffer
+		cli := New("cli", "1.0.0", "",
+			OptionStdio(nil, &buf, &buf),
+			OptionFileSystem(workDirFileSystem{dir: dir}),
+			OptionEnv(lookupEnv(env)),
+		)
+		cli.Add("greet", greetCmdFn)
+		cli.Add("load", loadCmdFn)
+		cli.Add("copy", func(ui UI) Command {
+			cmd := copyCmdFn(ui).(*copyCmd)
+			cmd.flagSet.String("mode", "", "How to copy")
+			return cmd
+		})
+
+		if _, err := cli.Run(nil); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+
+	for _, testcase := range []struct {
+		name, line, want string
+		describe         bool
+	}{
+		{name: "command", line: "cli gr", want: "greet\n"},
+		{name: "arguments", line: "cli greet fr", want: "fred\nfrank\n"},
+		{name: "files", line: "cli load con", want: "conf/\nconfig.yaml\ncontrib/\n"},
+		{name: "flag values", line: "cli greet --format y", want: "yaml\n"},
+		{name: "flag values with equals", line: "cli greet --format=y", want: "yaml\n"},
+		{name: "enum flag values", line: "cli load --mode r", want: "replace\n"},
+		{name: "positional arguments", line: "cli copy config.yaml ", want: "conf/\ncontrib/\n"},
+		{name: "positional arguments after flags", line: "cli copy --mode fast ", want: "conf/\nconfig.yaml\ncontrib/\n"},
+		{name: "descriptions", line: "cli gr", want: "greet:Greet someone.\n", describe: true},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			if expected, actual := testcase.want, complete(testcase.line, testcase.describe); expected != actual {
+				t.Errorf("expected: %q, actual: %q", expected, actual)
+			}
+		})
+	}
+}
+
+func TestAutoCompleteInstall(t *testing.T) {
+	t.Parallel()
+
+	for _, testcase := range []struct {
+		name  string
+		files []string
+		dirs  []string
+		check func(*testing.T, string)
+	}{
+		{
+			name:  "bash",
+			files: []string{".bashrc"},
+			check: func(t *testing.T, home string) {
+				content := readFile(t, filepath.Join(home, ".bashrc"))
+				if expected, actual := true, strings.HasPrefix(content, "\ncomplete -C ") && strings.HasSuffix(content, " cli\n"); expected != actual {
+					t.Errorf("expected: %v, actual: %v, content: %q", expected, actual, content)
+				}
+			},
+		},
+		{
+			name:  "zsh",
+			files: []string{".zshrc"},
+			check: func(t *testing.T, home string) {
+				content := readFile(t, filepath.Join(home, ".zsh", "completions", "_cli"))
+				if expected, actual := true, strings.HasPrefix(content, "#compdef cli\n"); expected != actual {
+					t.Errorf("expected: %v, actual: %v, content: %q", expected, actual, content)
+				}
+				completions := filepath.Join(home, ".zsh", "completions")
+				rc := readFile(t, filepath.Join(home, ".zshrc"))
+				if expected, actual := fmt.Sprintf("\n(( ${fpath[(Ie)%[1]s]} )) || fpath=(%[1]s $fpath)\n", completions), rc; expected != actual {
+					t.Errorf("expected: %q, actual: %q", expected, actual)
+				}
+			},
+		},
+		{
+			name:  "fish",
+			files: []string{".config/fish/config.fish"},
+			check: func(t *testing.T, home string) {
+				content := readFile(t, filepath.Join(home, ".config", "fish", "completions", "cli.fish"))
+				if expected, actual := true, strings.HasPrefix(content, "complete -c cli -f -a '(env COMP_LINE=(commandline -cp) "); expected != actual {
+					t.Errorf("expected: %v, actual: %v, content: %q", expected, actual, content)
+				}
+			},
+		},
+		{
+			name: "pwsh",
+			dirs: []string{".config/powershell"},
+			check: func(t *testing.T, home string) {
+				script := filepath.Join(home, ".config", "powershell", "completions", "cli.ps1")
+				content := readFile(t, script)
+				if expected, actual := true, strings.HasPrefix(content, "Register-ArgumentCompleter -Native -CommandName 'cli'"); expected != actual {
+					t.Errorf("expected: %v, actual: %v, content: %q", expected, actual, content)
+				}
+				profile := readFile(t, filepath.Join(home, ".config", "powershell", "Microsoft.PowerShell_profile.ps1"))
+				if expected, actual := fmt.Sprintf("\n. '%s'\n", script), profile; expected != actual {
+					t.Errorf("expected: %q, actual: %q", expected, actual)
+				}
+			},
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			home, err := ioutil.TempDir("", "home")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(home)
+
+			for _, path := range testcase.dirs {
+				if err := os.MkdirAll(filepath.Join(home, path), 0755); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for _, path := range testcase.files {
+				path = filepath.Join(home, path)
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := ioutil.WriteFile(path, nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var buf bytes.Buffer
+			cli := New("cli", "1.0.0", "",
+				OptionStdio(nil, &buf, &buf),
+				OptionFileSystem(fsys.NewLocalFileSystem()),
+				OptionUser(homeUser(home)),
+				OptionEnv(lookupEnv(map[string]string{
+					"HOME": home,
+				})),
+			)
+			cli.Add("greet", greetCmdFn)
+
+			code, err := cli.Run([]string{"--autocomplete-install"})
+			if expected, actual := true, err == nil; expected != actual {
+				t.Errorf("expected: %v, actual: %v, err: %v", expected, actual, err)
+			}
+			if expected, actual := EOK, code; expected != actual {
+				t.Errorf("expected: %v, actual: %v", expected, actual)
+			}
+			testcase.check(t, home)
+		})
+	}
+}
+
+func lookupEnv(env map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+type homeUser string
+
+func (u homeUser) HomeDir() string { return string(u) }
+
+// workDirFileSystem is the local file system, with a working directory that
+// isn't the working directory of the process.
+type workDirFileSystem struct {
+	fsys.LocalFileSystem
+	dir string
+}
+
+func (fs workDirFileSystem) Getwd() (string, error) {
+	return fs.dir, nil
+}
+
+func (fs workDirFileSystem) Stat(path string) (os.FileInfo, error) {
+	return fs.LocalFileSystem.Stat(fs.path(path))
+}
+
+func (fs workDirFileSystem) ReadDir(path string) ([]os.FileInfo, error) {
+	return fs.LocalFileSystem.ReadDir(fs.path(path))
+}
+
+func (fs workDirFileSystem) path(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(fs.dir, path)
+}
+
+type greetCmd struct {
+	ui      UI
+	flagSet *flagset.FlagSet
+	name    string
+}
+
+func greetCmdFn(ui UI) Command {
+	cmd := &greetCmd{
+		ui:      ui,
+		flagSet: flagset.New("greet", flag.ContinueOnError),
+	}
+	cmd.flagSet.StringVarP(&cmd.name, "name", "n", "world", "Name to greet")
+	return cmd
+}
+
+func (c *greetCmd) FlagSet() *flagset.FlagSet                    { return c.flagSet }
+func (c *greetCmd) Usages() []string                             { return []string{} }
+func (c *greetCmd) Help() string                                 { return "Greet someone." }
+func (c *greetCmd) Synopsis() string                             { return "Greet someone." }
+func (c *greetCmd) Init([]string, commands.CommandContext) error { return nil }
+
+func (c *greetCmd) Complete(*args.Args) []string {
+	return []string{"alice", "fred", "frank"}
+}
+
+func (c *greetCmd) Run(g *group.Group) {
+	c.ui.Output(ui.NewTemplate("Hello {{.Name}}!"), struct {
+		Name string `json:"name"`
+	}{
+		Name: c.name,
+	})
+	commands.Nothing(g)
+}
+
+type loadCmd struct {
+	flagSet *flagset.FlagSet
+}
+
+func loadCmdFn(UI) Command {
+	cmd := &loadCmd{flagSet: flagset.New("load", flag.ContinueOnError)}
+	cmd.flagSet.Enum("mode", "merge", []string{"merge", "replace"}, "How to load the file")
+	return cmd
+}
+
+func (c *loadCmd) FlagSet() *flagset.FlagSet                    { return c.flagSet }
+func (c *loadCmd) Usages() []string                             { return []string{"<file>"} }
+func (c *loadCmd) Help() string                                 { return "Load a file." }
+func (c *loadCmd) Synopsis() string                             { return "Load a file." }
+func (c *loadCmd) Init([]string, commands.CommandContext) error { return nil }
+func (c *loadCmd) Run(g *group.Group)                           { commands.Nothing(g) }
+
+func (c *loadCmd) Complete(a *args.Args) []string {
+	return args.PredictFiles("*.yaml").Predict(a)
+}
+
+type loginCmd struct {
+	flagSet *flagset.FlagSet
+}
+
+func (c *loginCmd) FlagSet() *flagset.FlagSet { return c.flagSet }
+func (c *loginCmd) Usages() []string          { return []string{} }
+func (c *loginCmd) Help() string              { return "Login to a server." }
+func (c *loginCmd) Synopsis() string          { return "Login to a server." }
+func (c *loginCmd) Run(g *group.Group)        { commands.Nothing(g) }
+
+func (c *loginCmd) Init([]string, commands.CommandContext) error {
+	return fmt.Errorf("init should not be called")
+}
+
+type copyCmd struct {
+	flagSet *flagset.FlagSet
+	argSet  *argset.ArgSet
+	ui      UI
+}
+
+func copyCmdFn(ui UI) Command {
+	argSet, err := argset.New(
+		argset.Arg{Name: "src", Type: argset.File("*.yaml")},
+		argset.Arg{Name: "dst", Type: argset.Dir(), Optional: true},
+	)
+	if err != nil {
+		panic(err)
+	}
+	return &copyCmd{
+		flagSet: flagset.New("copy", flag.ContinueOnError),
+		argSet:  argSet,
+		ui:      ui,
+	}
+}
+
+func (c *copyCmd) FlagSet() *flagset.FlagSet                    { return c.flagSet }
+func (c *copyCmd) ArgSet() *argset.ArgSet                       { return c.argSet }
+func (c *copyCmd) Usages() []string                             { return []string{} }
+func (c *copyCmd) Help() string                                 { return "Copy a file." }
+func (c *copyCmd) Synopsis() string                             { return "Copy a file." }
+func (c *copyCmd) Init([]string, commands.CommandContext) error { return nil }
+
+func (c *copyCmd) Run(g *group.Group) {
+	c.ui.Info(fmt.Sprintf("copy %s to %q", c.argSet.String("src"), c.argSet.String("dst")))
+	commands.Nothing(g)
+}
@@ -0,0 +1,245 @@
+package clitest
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/spoke-d/clui"
+)
+
+// DefaultHome is the home directory of the fake user, unless HOME is set in
+// the environment of the harness.
+const DefaultHome = "/home/test"
+
+// Env is a fake environment, that is used instead of the environment of the
+// process.
+type Env map[string]string
+
+// Lookup returns the value of the environment variable, in the same way as
+// os.LookupEnv.
+func (e Env) Lookup(key string) (string, bool) {
+	v, ok := e[key]
+	return v, ok
+}
+
+// User is a fake operating system user, with the home directory from the
+// environment.
+type User struct {
+	env Env
+}
+
+// HomeDir returns the home directory of the user.
+func (u User) HomeDir() string {
+	if home, ok := u.env["HOME"]; ok {
+		return home
+	}
+	return DefaultHome
+}
+
+// HarnessOptions represents a way to set optional values to a harness option.
+// The HarnessOptions shows what options are available to change.
+type HarnessOptions interface {
+	SetHeader(string)
+	SetEnv(map[string]string)
+	SetFiles(map[string]string)
+	AppendCLIOptions(...clui.CLIOption)
+}
+
+// HarnessOption captures a tweak that can be applied to the Harness.
+type HarnessOption func(HarnessOptions)
+
+type harness struct {
+	header     string
+	env        map[string]string
+	files      map[string]string
+	cliOptions []clui.CLIOption
+}
+
+func (s *harness) SetHeader(p string) {
+	s.header = p
+}
+
+func (s *harness) SetEnv(p map[string]string) {
+	s.env = p
+}
+
+func (s *harness) SetFiles(p map[string]string) {
+	s.files = p
+}
+
+func (s *harness) AppendCLIOptions(p ...clui.CLIOption) {
+	s.cliOptions = append(s.cliOptions, p...)
+}
+
+// OptionHeader allows the setting a header option to configure the harness.
+func OptionHeader(i string) HarnessOption {
+	return func(opt HarnessOptions) {
+		opt.SetHeader(i)
+	}
+}
+
+// OptionEnv allows the setting of the fake environment the CLI is run with.
+func OptionEnv(i map[string]string) HarnessOption {
+	return func(opt HarnessOptions) {
+		opt.SetEnv(i)
+	}
+}
+
+// OptionFiles allows the setting of the files in the fake file system, keyed
+// by path. The files are written before the CLI is created, so that the
+// autocomplete installer can find the shell files in the home directory.
+func OptionFiles(i map[string]string) HarnessOption {
+	return func(opt HarnessOptions) {
+		opt.SetFiles(i)
+	}
+}
+
+// OptionCLI allows the appending of options to configure the CLI. The options
+// are applied after the harness options, so they take precedence.
+func OptionCLI(i ...clui.CLIOption) HarnessOption {
+	return func(opt HarnessOptions) {
+		opt.AppendCLIOptions(i...)
+	}
+}
+
+// Result is the result of running the CLI.
+type Result struct {
+	Code   clui.Errno
+	Err    error
+	Stdout string
+	Stderr string
+}
+
+// Harness runs a CLI in process, with captured standard input and outputs, a
+// fake environment and an in memory file system.
+type Harness struct {
+	cli        *clui.CLI
+	env        Env
+	fileSystem *FileSystem
+
+	mutex  sync.Mutex
+	stdin  *buffer
+	stdout *buffer
+	stderr *buffer
+}
+
+// New creates a Harness for a CLI with the name and version.
+func New(name, version string, options ...HarnessOption) *Harness {
+	opt := new(harness)
+	for _, option := range options {
+		option(opt)
+	}
+
+	env := Env{
+		"HOME": DefaultHome,
+	}
+	for k, v := range opt.env {
+		env[k] = v
+	}
+
+	fileSystem := NewFileSystem()
+	for path, content := range opt.files {
+		fileSystem.WriteFile(path, content)
+	}
+
+	h := &Harness{
+		env:        env,
+		fileSystem: fileSystem,
+		stdin:      new(buffer),
+		stdout:     new(buffer),
+		stderr:     new(buffer),
+	}
+
+	cliOptions := append([]clui.CLIOption{
+		clui.OptionStdio(h.stdin, h.stdout, h.stderr),
+		clui.OptionEnv(env.Lookup),
+		clui.OptionFileSystem(fileSystem),
+		clui.OptionUser(User{env: env}),
+	}, opt.cliOptions...)
+
+	h.cli = clui.New(name, version, opt.header, cliOptions...)
+	return h
+}
+
+// Add inserts a new command to the CLI.
+func (h *Harness) Add(key string, cmdFn clui.CommandFn, options ...clui.CommandOption) error {
+	return h.cli.Add(key, cmdFn, options...)
+}
+
+// CLI returns the CLI that the harness runs.
+func (h *Harness) CLI() *clui.CLI {
+	return h.cli
+}
+
+// Env returns the fake environment. Changes to the environment are seen by
+// the next run.
+func (h *Harness) Env() Env {
+	return h.env
+}
+
+// FileSystem returns the fake file system.
+func (h *Harness) FileSystem() *FileSystem {
+	return h.fileSystem
+}
+
+// Run runs the CLI with the arguments and no input.
+func (h *Harness) Run(args ...string) Result {
+	return h.RunWithInput("", args...)
+}
+
+// RunWithInput runs the CLI with the arguments, with the input available on
+// the standard input.
+func (h *Harness) RunWithInput(input string, args ...string) Result {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.stdin.Reset()
+	h.stdout.Reset()
+	h.stderr.Reset()
+	h.stdin.WriteString(input)
+
+	code, err := h.cli.Run(args)
+	return Result{
+		Code:   code,
+		Err:    err,
+		Stdout: h.stdout.String(),
+		Stderr: h.stderr.String(),
+	}
+}
+
+// buffer is a bytes.Buffer that is safe to use from the goroutines that the
+// commands are run in.
+type buffer struct {
+	mutex sync.Mutex
+	buf   bytes.Buffer
+}
+
+func (b *buffer) Read(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buf.Read(p)
+}
+
+func (b *buffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *buffer) WriteString(s string) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buf.WriteString(s)
+}
+
+func (b *buffer) Reset() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.buf.Reset()
+}
+
+func (b *buffer) String() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buf.String()
+}
@@ -0,0 +1,179 @@
+package clitest
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/spoke-d/clui"
+	"github.com/spoke-d/clui/commands"
+	"github.com/spoke-d/clui/flagset"
+	"github.com/spoke-d/clui/ui"
+	"github.com/spoke-d/task/group"
+)
+
+type greetCmd struct {
+	ui      clui.UI
+	flagSet *flagset.FlagSet
+	name    string
+	ask     bool
+}
+
+func greetCmdFn(ui clui.UI) clui.Command {
+	cmd := &greetCmd{
+		ui:      ui,
+		flagSet: flagset.New("greet", flag.ContinueOnError),
+	}
+	cmd.flagSet.StringVarP(&cmd.name, "name", "n", "world", "Name to greet")
+	cmd.flagSet.BoolVar(&cmd.ask, "ask", false, "Ask for the name")
+	return cmd
+}
+
+func (c *greetCmd) FlagSet() *flagset.FlagSet { return c.flagSet }
+func (c *greetCmd) Usages() []string          { return []string{} }
+func (c *greetCmd) Help() string              { return "Greet someone." }
+func (c *greetCmd) Synopsis() string          { return "Greet someone." }
+
+func (c *greetCmd) Init([]string, commands.CommandContext) error {
+	if !c.ask {
+		return nil
+	}
+	name, err := c.ui.Ask("Name?")
+	if err != nil {
+		return err
+	}
+	c.name = name
+	return nil
+}
+
+func (c *greetCmd) Run(g *group.Group) {
+	c.ui.Output(ui.NewTemplate("Hello {{.Name}}!"), struct {
+		Name string `json:"name"`
+	}{
+		Name: c.name,
+	})
+	commands.Nothing(g)
+}
+
+func TestHarness(t *testing.T) {
+	t.Parallel()
+
+	t.Run("run", func(t *testing.T) {
+		h := New("cli", "1.0.0")
+		h.Add("greet", greetCmdFn)
+
+		res := h.Run("greet", "--name=fred")
+		if expected, actual := true, res.Err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v, err: %v", expected, actual, res.Err)
+		}
+		if expected, actual := clui.EOK, res.Code; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := "Hello fred!\n", res.Stdout; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("help", func(t *testing.T) {
+		h := New("cli", "1.0.0")
+		h.Add("greet", greetCmdFn)
+
+		res := h.Run("greet", "--help")
+		res.AssertStdout(t, "testdata/greet-help.golden")
+	})
+
+	t.Run("stdin", func(t *testing.T) {
+		h := New("cli", "1.0.0")
+		h.Add("greet", greetCmdFn)
+
+		res := h.RunWithInput("fred\n", "greet", "--ask")
+		if expected, actual := "Name? Hello fred!\n", res.Stdout; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+	})
+
+	t.Run("env", func(t *testing.T) {
+		h := New("cli", "1.0.0", OptionEnv(map[string]string{
+			"NAME": "env",
+		}))
+		h.Add("greet", greetCmdFn)
+
+		res := h.Run("greet")
+		if expected, actual := "Hello env!\n", res.Stdout; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("env changes", func(t *testing.T) {
+		h := New("cli", "1.0.0")
+		h.Add("greet", greetCmdFn)
+
+		h.Env()["NAME"] = "env"
+
+		res := h.Run("greet")
+		if expected, actual := "Hello env!\n", res.Stdout; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("cli options", func(t *testing.T) {
+		h := New("cli", "1.0.0",
+			OptionEnv(map[string]string{
+				"NAME":     "bare",
+				"CLI_NAME": "env",
+			}),
+			OptionCLI(clui.OptionEnvPrefix("cli")),
+		)
+		h.Add("greet", greetCmdFn)
+
+		res := h.Run("greet")
+		if expected, actual := "Hello env!\n", res.Stdout; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("files", func(t *testing.T) {
+		h := New("cli", "1.0.0", OptionFiles(map[string]string{
+			"/home/test/.config/cli/config.toml": "[greet]\nname = \"config\"\n",
+		}))
+		h.Add("greet", greetCmdFn)
+
+		res := h.Run("greet")
+		if expected, actual := "Hello config!\n", res.Stdout; expected != actual {
+			t.Errorf("expected: %v, actual: %v, err: %v", expected, actual, res.Err)
+		}
+	})
+}
+
+func TestFileSystem(t *testing.T) {
+	t.Parallel()
+
+	fs := NewFileSystem()
+	if _, err := fs.Open("/missing"); err == nil {
+		t.Errorf("expected error opening a missing file")
+	}
+
+	f, err := fs.Create("/file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte("hello "))
+	f.Write([]byte("world"))
+	f.Close()
+
+	if expected, actual := int64(11), f.Size(); expected != actual {
+		t.Errorf("expected: %v, actual: %v", expected, actual)
+	}
+	content, ok := fs.ReadFile("/file")
+	if expected, actual := "hello world", content; !ok || expected != actual {
+		t.Errorf("expected: %v, actual: %v", expected, actual)
+	}
+	if expected, actual := true, fs.Exists("/file"); expected != actual {
+		t.Errorf("expected: %v, actual: %v", expected, actual)
+	}
+	if err := fs.Remove("/file"); err != nil {
+		t.Fatal(err)
+	}
+	if expected, actual := false, fs.Exists("/file"); expected != actual {
+		t.Errorf("expected: %v, actual: %v", expected, actual)
+	}
+}
@@ -0,0 +1,307 @@
+package clitest
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spoke-d/clui/autocomplete/fsys"
+)
+
+// FileSystem is an in memory implementation of fsys.FileSystem.
+type FileSystem struct {
+	mutex sync.Mutex
+	files map[string][]byte
+	dirs  map[string]struct{}
+	wd    string
+}
+
+// NewFileSystem creates an empty in memory FileSystem.
+func NewFileSystem() *FileSystem {
+	return &FileSystem{
+		files: make(map[string][]byte),
+		dirs:  make(map[string]struct{}),
+		wd:    "/",
+	}
+}
+
+// Create takes a path, creates the file and then returns a File back that
+// can be used. If the file already exists, it is truncated.
+func (fs *FileSystem) Create(path string) (fsys.File, error) {
+	return fs.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666)
+}
+
+// Open takes a path, opens a potential file and then returns a File if
+// that file exists, otherwise it returns an error if the file wasn't found.
+func (fs *FileSystem) Open(path string) (fsys.File, error) {
+	return fs.OpenFile(path, os.O_RDONLY, 0)
+}
+
+// OpenFile takes a path, opens a potential file and then returns a File if
+// that file exists, otherwise it returns an error if the file wasn't found.
+// Writes are always appended to the file.
+func (fs *FileSystem) OpenFile(path string, flag int, perm os.FileMode) (fsys.File, error) {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	content, ok := fs.files[path]
+	if !ok {
+		if flag&os.O_CREATE == 0 {
+			return nil, &os.PathError{Op: "open", Path: path, Err: os.ErrNotExist}
+		}
+		content = make([]byte, 0)
+	}
+	if flag&os.O_TRUNC != 0 {
+		content = make([]byte, 0)
+	}
+	fs.files[path] = content
+
+	return &file{
+		fs:     fs,
+		name:   path,
+		reader: bytes.NewReader(append([]byte(nil), content...)),
+	}, nil
+}
+
+// Exists takes a path and checks to see if the potential file exists or
+// not. Directories exist if they've been created, or if they contain a file.
+func (fs *FileSystem) Exists(path string) bool {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	if _, ok := fs.files[path]; ok {
+		return true
+	}
+	if _, ok := fs.dirs[path]; ok {
+		return true
+	}
+	prefix := strings.TrimSuffix(path, "/") + "/"
+	for name := range fs.files {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Remove takes a path, removes a potential file, if no file doesn't exist it
+// will return not found.
+func (fs *FileSystem) Remove(path string) error {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	if _, ok := fs.files[path]; !ok {
+		return &os.PathError{Op: "remove", Path: path, Err: os.ErrNotExist}
+	}
+	delete(fs.files, path)
+	return nil
+}
+
+// MkdirAll takes a path and creates the directory, along with any parents
+// that don't exist yet.
+func (fs *FileSystem) MkdirAll(path string) error {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	for dir := filepath.Clean(path); dir != "/" && dir != "."; dir = filepath.Dir(dir) {
+		fs.dirs[dir] = struct{}{}
+	}
+	return nil
+}
+
+// Stat returns a FileInfo describing the named file.
+func (fs *FileSystem) Stat(path string) (os.FileInfo, error) {
+	path = fs.abs(path)
+	if !fs.Exists(path) {
+		return nil, &os.PathError{Op: "stat", Path: path, Err: os.ErrNotExist}
+	}
+
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	content, ok := fs.files[path]
+	return fileInfo{
+		name: filepath.Base(path),
+		size: int64(len(content)),
+		dir:  !ok,
+	}, nil
+}
+
+// Getwd returns the working directory of the filesystem, which is "/" unless
+// it's changed with Chdir.
+func (fs *FileSystem) Getwd() (string, error) {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	return fs.wd, nil
+}
+
+// Chdir changes the working directory of the filesystem, which relative paths
+// are resolved against.
+func (fs *FileSystem) Chdir(dir string) {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	fs.wd = filepath.Clean(dir)
+}
+
+// ReadDir reads the directory named by dirname and returns a list of
+// directory entries sorted by filename.
+func (fs *FileSystem) ReadDir(path string) ([]os.FileInfo, error) {
+	info, err := fs.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, &os.PathError{Op: "readdir", Path: path, Err: os.ErrInvalid}
+	}
+
+	dir := fs.abs(path)
+
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	entries := make(map[string]fileInfo)
+	add := func(name string, isFile bool) {
+		rel, err := filepath.Rel(dir, name)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			return
+		}
+		parts := strings.SplitN(rel, string(filepath.Separator), 2)
+		entry := fileInfo{
+			name: parts[0],
+			dir:  len(parts) > 1 || !isFile,
+		}
+		if !entry.dir {
+			entry.size = int64(len(fs.files[name]))
+		}
+		entries[parts[0]] = entry
+	}
+	for name := range fs.files {
+		add(name, true)
+	}
+	for name := range fs.dirs {
+		add(name, false)
+	}
+
+	infos := make([]os.FileInfo, 0, len(entries))
+	for _, entry := range entries {
+		infos = append(infos, entry)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name() < infos[j].Name()
+	})
+	return infos, nil
+}
+
+// WriteFile writes the content to the file at the path, replacing the file if
+// it already exists.
+func (fs *FileSystem) WriteFile(path, content string) {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	fs.files[path] = []byte(content)
+}
+
+// ReadFile returns the content of the file at the path.
+// Returns false if the file doesn't exist.
+func (fs *FileSystem) ReadFile(path string) (string, bool) {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	content, ok := fs.files[path]
+	return string(content), ok
+}
+
+// Paths returns the paths of all the files, sorted.
+func (fs *FileSystem) Paths() []string {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	paths := make([]string, 0, len(fs.files))
+	for path := range fs.files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func (fs *FileSystem) abs(path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	return filepath.Join(fs.wd, path)
+}
+
+func (fs *FileSystem) append(path string, p []byte) {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	fs.files[path] = append(fs.files[path], p...)
+}
+
+func (fs *FileSystem) size(path string) int64 {
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	return int64(len(fs.files[path]))
+}
+
+// file is a handle to a file in the FileSystem. Reads see the content of the
+// file when it was opened.
+type file struct {
+	fs     *FileSystem
+	name   string
+	reader *bytes.Reader
+}
+
+func (f *file) Read(p []byte) (int, error) {
+	return f.reader.Read(p)
+}
+
+func (f *file) Write(p []byte) (int, error) {
+	f.fs.append(f.name, p)
+	return len(p), nil
+}
+
+func (f *file) Close() error {
+	return nil
+}
+
+func (f *file) Name() string {
+	return f.name
+}
+
+func (f *file) Size() int64 {
+	return f.fs.size(f.name)
+}
+
+func (f *file) Sync() error {
+	return nil
+}
+
+type fileInfo struct {
+	name string
+	size int64
+	dir  bool
+}
+
+func (i fileInfo) Name() string       { return i.name }
+func (i fileInfo) Size() int64        { return i.size }
+func (i fileInfo) ModTime() time.Time { return time.Time{} }
+func (i fileInfo) IsDir() bool        { return i.dir }
+func (i fileInfo) Sys() interface{}   { return nil }
+
+func (i fileInfo) Mode() os.FileMode {
+	if i.dir {
+		return os.ModeDir | 0755
+	}
+	return 0644
+}
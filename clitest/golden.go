@@ -0,0 +1,48 @@
+package clitest
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("clitest.update", false, "update the golden files")
+
+// AssertGolden compares the actual output with the content of the golden file
+// at the path. Running the tests with -clitest.update writes the actual output
+// to the golden file instead.
+func AssertGolden(t testing.TB, path, actual string) {
+	t.Helper()
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(actual), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	expected, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("error reading golden file %q: %v", path, err)
+	}
+	if string(expected) != actual {
+		t.Errorf("golden file %q does not match\nexpected: %q\nactual: %q", path, string(expected), actual)
+	}
+}
+
+// AssertStdout compares the standard output with the golden file at the path.
+func (r Result) AssertStdout(t testing.TB, path string) {
+	t.Helper()
+	AssertGolden(t, path, r.Stdout)
+}
+
+// AssertStderr compares the standard error with the golden file at the path.
+func (r Result) AssertStderr(t testing.TB, path string) {
+	t.Helper()
+	AssertGolden(t, path, r.Stderr)
+}
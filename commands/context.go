@@ -1,7 +1,13 @@
 package commands
 
+import "github.com/spoke-d/clui/flagset"
+
 // CommandContext is the context the command was run with.
 type CommandContext struct {
 	Debug   bool
 	DevMode bool
+
+	// GlobalFlags holds the global flags that were parsed before the command
+	// was resolved.
+	GlobalFlags *flagset.FlagSet
 }
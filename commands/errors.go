@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+)
+
+// UsageError represents a mistake in how a command was called, such as an
+// invalid flag or argument. Returning a UsageError will show the usage of the
+// command along with the error.
+type UsageError struct {
+	err error
+}
+
+// NewUsageError creates a UsageError from the given error.
+func NewUsageError(err error) *UsageError {
+	return &UsageError{
+		err: err,
+	}
+}
+
+// Usagef creates a UsageError with the given format and arguments.
+func Usagef(format string, args ...interface{}) *UsageError {
+	return NewUsageError(fmt.Errorf(format, args...))
+}
+
+func (e *UsageError) Error() string {
+	if e.err == nil {
+		return ""
+	}
+	return e.err.Error()
+}
+
+// Cause returns the underlying cause of the error.
+func (e *UsageError) Cause() error {
+	return e.err
+}
+
+// Unwrap returns the underlying error.
+func (e *UsageError) Unwrap() error {
+	return e.err
+}
+
+// IsUsageError returns if the error, or any error it wraps, is a UsageError.
+func IsUsageError(err error) bool {
+	var e *UsageError
+	return errors.As(err, &e)
+}
@@ -0,0 +1,34 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestUsageError(t *testing.T) {
+	t.Parallel()
+
+	t.Run("usage error", func(t *testing.T) {
+		err := Usagef("missing %s", "name")
+		if expected, actual := "missing name", err.Error(); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := true, IsUsageError(err); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("wrapped usage error", func(t *testing.T) {
+		err := fmt.Errorf("init: %w", NewUsageError(errors.New("bad")))
+		if expected, actual := true, IsUsageError(err); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("not usage error", func(t *testing.T) {
+		if expected, actual := false, IsUsageError(errors.New("bad")); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+}
@@ -26,6 +26,38 @@ type Runnable interface {
 type Store interface {
 	// WalkPrefix is used to walk the tree under a prefix
 	WalkPrefix(prefix string, fn radix.WalkFn)
+
+	// Hidden returns if the command for a given key should be hidden.
+	Hidden(key string) bool
+}
+
+// ShellOptions represents a way to set optional values to a shell option.
+// The ShellOptions shows what options are available to change.
+type ShellOptions interface {
+	SetStdio(io.Reader, io.Writer, io.Writer)
+}
+
+// ShellOption captures a tweak that can be applied to the Shell.
+type ShellOption func(ShellOptions)
+
+type shell struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func (s *shell) SetStdio(stdin io.Reader, stdout, stderr io.Writer) {
+	s.stdin = stdin
+	s.stdout = stdout
+	s.stderr = stderr
+}
+
+// OptionStdio allows the setting of the standard input and outputs that the
+// shell reads from and writes to.
+func OptionStdio(stdin io.Reader, stdout, stderr io.Writer) ShellOption {
+	return func(opt ShellOptions) {
+		opt.SetStdio(stdin, stdout, stderr)
+	}
 }
 
 // Shell defines a REPL that can be interactively accessed.
@@ -33,14 +65,29 @@ type Shell struct {
 	flagSet *flagset.FlagSet
 	runner  Runnable
 	group   Store
+	stdin   io.Reader
+	stdout  io.Writer
+	stderr  io.Writer
 }
 
 // NewShell creates a REPL from a runnable and a command store.
-func NewShell(runner Runnable, group Store) *Shell {
+func NewShell(runner Runnable, group Store, options ...ShellOption) *Shell {
+	opt := &shell{
+		stdin:  os.Stdin,
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+	}
+	for _, option := range options {
+		option(opt)
+	}
+
 	return &Shell{
 		flagSet: flagset.New("text-command", flag.ContinueOnError),
 		runner:  runner,
 		group:   group,
+		stdin:   opt.stdin,
+		stdout:  opt.stdout,
+		stderr:  opt.stderr,
 	}
 }
 
@@ -98,9 +145,9 @@ func (c *Shell) Run(group *group.Group) {
 
 		line, err := readline.NewEx(&readline.Config{
 			HistoryFile:     history.Name(),
-			Stdin:           readline.NewCancelableStdin(os.Stdin),
-			Stdout:          os.Stdout,
-			Stderr:          os.Stderr,
+			Stdin:           readline.NewCancelableStdin(c.stdin),
+			Stdout:          c.stdout,
+			Stderr:          c.stderr,
 			Prompt:          "\033[31m»\033[0m ",
 			InterruptPrompt: "^C",
 			EOFPrompt:       "exit",
@@ -114,7 +161,7 @@ func (c *Shell) Run(group *group.Group) {
 		first := true
 		for {
 			if first {
-				fmt.Fprintln(os.Stdout, firstPrompt)
+				fmt.Fprintln(c.stdout, firstPrompt)
 				first = false
 			}
 
@@ -130,17 +177,17 @@ func (c *Shell) Run(group *group.Group) {
 			}
 
 			if cmd := strings.TrimSpace(data); cmd == "help commands" {
-				fmt.Fprintln(os.Stdout, listAllCommands(c.group))
+				fmt.Fprintln(c.stdout, listAllCommands(c.group))
 				continue
 			} else if cmd == "help" {
-				fmt.Fprintln(os.Stdout, "Type ^D or ^C to exit the shell.")
+				fmt.Fprintln(c.stdout, "Type ^D or ^C to exit the shell.")
 				continue
 			} else if cmd == "exit" {
 				return nil
 			}
 
 			if _, err := c.runner.Run(strings.Split(data, " ")); err != nil {
-				fmt.Fprintln(os.Stderr, err)
+				fmt.Fprintln(c.stderr, err)
 			}
 		}
 
@@ -151,6 +198,9 @@ func (c *Shell) Run(group *group.Group) {
 func listAllCommands(group Store) string {
 	var commands []string
 	group.WalkPrefix("", func(name string, value radix.Value) bool {
+		if group.Hidden(name) {
+			return false
+		}
 		commands = append(commands, name)
 		return false
 	})
@@ -163,6 +213,9 @@ func generateCompletions(group Store) *readline.PrefixCompleter {
 		children: make(map[string]node),
 	}
 	group.WalkPrefix("", func(name string, value radix.Value) bool {
+		if group.Hidden(name) {
+			return false
+		}
 		names := strings.Split(name, " ")
 		parent := nodes
 		for _, n := range names {
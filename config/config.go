@@ -0,0 +1,251 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Extensions are the config file extensions that are supported, in the order
+// they're searched for.
+var Extensions = []string{".toml", ".json", ".yaml", ".yml"}
+
+// Config holds the values of a config file, grouped into sections. Sections
+// are keyed by the command path, with each name separated by a dot, so the
+// "config show" command reads from the "config.show" section. Values that
+// aren't in a section apply to all commands.
+type Config struct {
+	path     string
+	sections map[string]map[string]string
+}
+
+// New creates an empty Config.
+func New() *Config {
+	return &Config{
+		sections: make(map[string]map[string]string),
+	}
+}
+
+// DefaultPath returns the path of the config file for the named CLI, from the
+// user config directory (XDG_CONFIG_HOME on unix). The first file that exists
+// with one of the supported extensions is used. The environment variables are
+// looked up with lookupEnv and the files are checked with exists, which
+// default to os.LookupEnv and os.Stat when nil.
+// Returns false if no config file exists.
+func DefaultPath(name string, lookupEnv func(string) (string, bool), exists func(string) bool) (string, bool) {
+	if lookupEnv == nil {
+		lookupEnv = os.LookupEnv
+	}
+	if exists == nil {
+		exists = fileExists
+	}
+
+	dir, ok := userConfigDir(lookupEnv)
+	if !ok {
+		return "", false
+	}
+	for _, path := range Paths(dir, name) {
+		if exists(path) {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// Paths returns the paths that are searched for the config file of the named
+// CLI in a config directory, in the order they're searched.
+func Paths(dir, name string) []string {
+	paths := make([]string, len(Extensions))
+	for i, ext := range Extensions {
+		paths[i] = filepath.Join(dir, name, "config"+ext)
+	}
+	return paths
+}
+
+// Load reads the config file at the path, using the extension of the file to
+// determine the format.
+func Load(path string) (*Config, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer file.Close()
+
+	return Read(path, file)
+}
+
+// Read reads a config file from the reader, using the extension of the path
+// to determine the format.
+func Read(path string, r io.Reader) (*Config, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var (
+		values map[string]interface{}
+		ext    = strings.ToLower(filepath.Ext(path))
+	)
+	switch ext {
+	case ".json":
+		d := json.NewDecoder(bytes.NewReader(b))
+		d.UseNumber()
+		err = d.Decode(&values)
+	case ".toml":
+		_, err = toml.Decode(string(b), &values)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(b, &values)
+	default:
+		return nil, errors.Errorf("unsupported config file extension %q", ext)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "error parsing config file %q", path)
+	}
+
+	config := New()
+	config.path = path
+	config.add("", values)
+	return config, nil
+}
+
+// Path returns the path of the config file. The path is empty if the config
+// wasn't loaded from a file.
+func (c *Config) Path() string {
+	return c.path
+}
+
+// Set sets the value for a key in the section for a command.
+func (c *Config) Set(command, key, value string) {
+	section := sectionName(command)
+	if _, ok := c.sections[section]; !ok {
+		c.sections[section] = make(map[string]string)
+	}
+	c.sections[section][key] = value
+}
+
+// Sections returns the names of all the sections, sorted.
+func (c *Config) Sections() []string {
+	var names []string
+	for name := range c.sections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Values returns the values for a command. The values that aren't in a
+// section are returned, along with the values from the section for the
+// command, which take precedence.
+func (c *Config) Values(command string) map[string]string {
+	values := make(map[string]string)
+	for k, v := range c.sections[""] {
+		values[k] = v
+	}
+	if section := sectionName(command); section != "" {
+		for k, v := range c.sections[section] {
+			values[k] = v
+		}
+	}
+	return values
+}
+
+// userConfigDir returns the user config directory in the same way as
+// os.UserConfigDir, but with the environment variables from lookupEnv.
+func userConfigDir(lookupEnv func(string) (string, bool)) (string, bool) {
+	env := func(key string) string {
+		value, _ := lookupEnv(key)
+		return value
+	}
+
+	var dir string
+	switch runtime.GOOS {
+	case "windows":
+		dir = env("AppData")
+	case "darwin", "ios":
+		if home := env("HOME"); home != "" {
+			dir = filepath.Join(home, "Library", "Application Support")
+		}
+	case "plan9":
+		if home := env("home"); home != "" {
+			dir = filepath.Join(home, "lib")
+		}
+	default:
+		dir = env("XDG_CONFIG_HOME")
+		if dir == "" {
+			if home := env("HOME"); home != "" {
+				dir = filepath.Join(home, ".config")
+			}
+		}
+	}
+	return dir, dir != ""
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+func (c *Config) add(section string, values map[string]interface{}) {
+	for k, v := range values {
+		if m, ok := stringMap(v); ok {
+			name := k
+			if section != "" {
+				name = section + "." + k
+			}
+			c.add(name, m)
+			continue
+		}
+		if _, ok := c.sections[section]; !ok {
+			c.sections[section] = make(map[string]string)
+		}
+		c.sections[section][k] = valueString(v)
+	}
+}
+
+// stringMap returns the value as a map with string keys, if it's a table.
+// YAML tables are decoded with interface keys, so they're converted.
+func stringMap(v interface{}) (map[string]interface{}, bool) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		return t, true
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, v := range t {
+			m[fmt.Sprint(k)] = v
+		}
+		return m, true
+	}
+	return nil, false
+}
+
+func sectionName(command string) string {
+	return strings.Join(strings.Fields(command), ".")
+}
+
+func valueString(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case []interface{}:
+		parts := make([]string, len(t))
+		for i, p := range t {
+			parts[i] = valueString(p)
+		}
+		return strings.Join(parts, ",")
+	default:
+		return fmt.Sprint(t)
+	}
+}
@@ -0,0 +1,226 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	files := map[string]string{
+		"config.toml": `
+# top level values apply to every command
+debug = true
+
+[config.show]
+server = "http://localhost:8080" # trailing comment
+retries = 3
+tags = ["a", "b"]
+`,
+		"config.json": `{
+	"debug": true,
+	"config": {
+		"show": {
+			"server": "http://localhost:8080",
+			"retries": 3,
+			"tags": ["a", "b"]
+		}
+	}
+}`,
+		"config.yaml": `
+# top level values apply to every command
+debug: true
+config:
+  show:
+    server: "http://localhost:8080" # trailing comment
+    retries: 3
+    tags:
+      - a
+      - 'b'
+`,
+	}
+
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			path := writeConfig(t, name, content)
+			defer os.RemoveAll(filepath.Dir(path))
+
+			config, err := Load(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expected, actual := path, config.Path(); expected != actual {
+				t.Errorf("expected: %v, actual: %v", expected, actual)
+			}
+			if expected, actual := []string{"", "config.show"}, config.Sections(); !reflect.DeepEqual(expected, actual) {
+				t.Errorf("expected: %v, actual: %v", expected, actual)
+			}
+
+			want := map[string]string{
+				"debug":   "true",
+				"server":  "http://localhost:8080",
+				"retries": "3",
+				"tags":    "a,b",
+			}
+			if expected, actual := want, config.Values("config show"); !reflect.DeepEqual(expected, actual) {
+				t.Errorf("expected: %v, actual: %v", expected, actual)
+			}
+			if expected, actual := map[string]string{"debug": "true"}, config.Values("version"); !reflect.DeepEqual(expected, actual) {
+				t.Errorf("expected: %v, actual: %v", expected, actual)
+			}
+		})
+	}
+
+	syntax := map[string]string{
+		"config.toml": `
+config = { show = { server = "http://localhost:8080" } }
+motd = """
+hello
+world"""
+`,
+		"config.yaml": `
+config: {show: {server: "http://localhost:8080"}}
+motd: |-
+  hello
+  world
+`,
+	}
+
+	for name, content := range syntax {
+		t.Run(name+" syntax", func(t *testing.T) {
+			path := writeConfig(t, name, content)
+			defer os.RemoveAll(filepath.Dir(path))
+
+			config, err := Load(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := map[string]string{
+				"server": "http://localhost:8080",
+				"motd":   "hello\nworld",
+			}
+			if expected, actual := want, config.Values("config show"); !reflect.DeepEqual(expected, actual) {
+				t.Errorf("expected: %v, actual: %v", expected, actual)
+			}
+		})
+	}
+
+	t.Run("unsupported", func(t *testing.T) {
+		path := writeConfig(t, "config.ini", "")
+		defer os.RemoveAll(filepath.Dir(path))
+
+		_, err := Load(path)
+		if expected, actual := `unsupported config file extension ".ini"`, err.Error(); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		path := writeConfig(t, "config.toml", "[config.show]\nserver\n")
+		defer os.RemoveAll(filepath.Dir(path))
+
+		_, err := Load(path)
+		if expected, actual := `error parsing config file "`+path+`": Near line 1 (last key parsed 'config.show'): bare keys cannot contain '\n'`, err.Error(); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+}
+
+func TestConfig(t *testing.T) {
+	t.Parallel()
+
+	t.Run("section precedence", func(t *testing.T) {
+		config := New()
+		config.Set("", "server", "global")
+		config.Set("config show", "server", "local")
+
+		if expected, actual := "local", config.Values("config show")["server"]; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := "global", config.Values("config")["server"]; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+}
+
+func TestDefaultPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	home := os.Getenv("XDG_CONFIG_HOME")
+	os.Setenv("XDG_CONFIG_HOME", dir)
+	defer os.Setenv("XDG_CONFIG_HOME", home)
+
+	if _, ok := DefaultPath("cli", nil, nil); ok {
+		t.Errorf("expected no config file")
+	}
+
+	path := filepath.Join(dir, "cli", "config.yaml")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, []byte("debug: true\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	actual, ok := DefaultPath("cli", nil, nil)
+	if expected, actual := true, ok; expected != actual {
+		t.Errorf("expected: %v, actual: %v", expected, actual)
+	}
+	if expected := path; expected != actual {
+		t.Errorf("expected: %v, actual: %v", expected, actual)
+	}
+}
+
+func TestDefaultPathWithEnv(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" || runtime.GOOS == "plan9" {
+		t.Skip("config dir isn't read from XDG_CONFIG_HOME")
+	}
+
+	env := map[string]string{
+		"HOME": "/home/test",
+	}
+	lookupEnv := func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+	exists := func(path string) bool {
+		return path == "/home/test/.config/cli/config.json"
+	}
+
+	path, ok := DefaultPath("cli", lookupEnv, exists)
+	if expected, actual := true, ok; expected != actual {
+		t.Errorf("expected: %v, actual: %v", expected, actual)
+	}
+	if expected, actual := "/home/test/.config/cli/config.json", path; expected != actual {
+		t.Errorf("expected: %v, actual: %v", expected, actual)
+	}
+
+	env["XDG_CONFIG_HOME"] = "/xdg"
+	if _, ok := DefaultPath("cli", lookupEnv, exists); ok {
+		t.Errorf("expected no config file")
+	}
+}
+
+func writeConfig(t *testing.T, name, content string) string {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
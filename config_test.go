@@ -0,0 +1,229 @@
+package clui
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/spoke-d/clui/autocomplete/fsys"
+	"github.com/spoke-d/clui/commands"
+	"github.com/spoke-d/clui/flagset"
+	"github.com/spoke-d/clui/ui"
+)
+
+func TestConfigFile(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.toml")
+	content := "server = \"global\"\n\n[foo.bar]\nserver = \"local\"\nretries = 3\n"
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("config", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		flags := flagset.New("foo bar", flag.ContinueOnError)
+		server := flags.String("server", "default", "")
+		retries := flags.Int("retries", 1, "")
+		timeout := flags.Int("timeout", 1, "")
+
+		cmd := NewMockCommand(ctrl)
+		cmd.EXPECT().FlagSet().Return(flags)
+		cmd.EXPECT().Init(gomock.Any(), gomock.Any()).Return(nil)
+		cmd.EXPECT().Run(gomock.Any()).Do(commands.Nothing)
+
+		var buf bytes.Buffer
+		cli := New("cli", "1.0.0", "",
+			OptionUI(ui.NewBasicUI(nil, &buf, &buf)),
+			OptionAutoCompleter(nopAutoCompleter{}),
+		)
+		cli.Add("foo bar", func(UI) Command { return cmd })
+
+		code, err := cli.Run([]string{"foo", "bar", "--config", path, "--retries=5"})
+		if expected, actual := true, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v, err: %v", expected, actual, err)
+		}
+		if expected, actual := EOK, code; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := "local", *server; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := flagset.OriginConfig, flags.Origin("server"); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := 5, *retries; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := flagset.OriginFlag, flags.Origin("retries"); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := 1, *timeout; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := flagset.OriginDefault, flags.Origin("timeout"); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("missing config", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		cmd := NewMockCommand(ctrl)
+
+		var buf bytes.Buffer
+		cli := New("cli", "1.0.0", "",
+			OptionUI(ui.NewBasicUI(nil, &buf, &buf)),
+			OptionAutoCompleter(nopAutoCompleter{}),
+		)
+		cli.Add("foo bar", func(UI) Command { return cmd })
+
+		code, err := cli.Run([]string{"foo", "bar", "--config", filepath.Join(dir, "missing.toml")})
+		if expected, actual := false, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v, err: %v", expected, actual, err)
+		}
+		if expected, actual := EPerm, code; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("help with missing config", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		cmd := NewMockCommand(ctrl)
+		cmd.EXPECT().FlagSet().Return(flagset.New("foo bar", flag.ContinueOnError)).Times(2)
+		cmd.EXPECT().Help().Return("Bar things.")
+		cmd.EXPECT().Usages().Return(nil)
+
+		var buf bytes.Buffer
+		cli := New("cli", "1.0.0", "",
+			OptionUI(ui.NewBasicUI(nil, &buf, &buf)),
+			OptionAutoCompleter(nopAutoCompleter{}),
+		)
+		cli.Add("foo bar", func(UI) Command { return cmd })
+
+		code, err := cli.Run([]string{"foo", "bar", "--help", "--config", filepath.Join(dir, "missing.toml")})
+		if expected, actual := true, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v, err: %v", expected, actual, err)
+		}
+		if expected, actual := EOK, code; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := true, bytes.Contains(buf.Bytes(), []byte("Bar things.")); expected != actual {
+			t.Errorf("expected: %v, actual: %v, output: %s", expected, actual, buf.String())
+		}
+	})
+	t.Run("default config", func(t *testing.T) {
+		home, err := ioutil.TempDir("", "home")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(home)
+
+		path := filepath.Join(home, "cli", "config.toml")
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte("[greet]\nname = \"config\"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		var buf bytes.Buffer
+		cli := New("cli", "1.0.0", "",
+			OptionStdio(nil, &buf, &buf),
+			OptionAutoCompleter(nopAutoCompleter{}),
+			OptionEnv(lookupEnv(map[string]string{
+				"XDG_CONFIG_HOME": home,
+			})),
+		)
+		cli.Add("greet", greetCmdFn)
+
+		_, err = cli.Run([]string{"greet"})
+		if expected, actual := "Hello config!\n", buf.String(); expected != actual {
+			t.Errorf("expected: %q, actual: %q, err: %v", expected, actual, err)
+		}
+	})
+}
+
+func TestEnv(t *testing.T) {
+	t.Parallel()
+
+	t.Run("env", func(t *testing.T) {
+		var buf bytes.Buffer
+		cli := New("cli", "1.0.0", "",
+			OptionStdio(nil, &buf, &buf),
+			OptionAutoCompleter(nopAutoCompleter{}),
+			OptionEnv(lookupEnv(map[string]string{
+				"NAME": "env",
+			})),
+		)
+		cli.Add("greet", greetCmdFn)
+
+		_, err := cli.Run([]string{"greet"})
+		if expected, actual := "Hello env!\n", buf.String(); expected != actual {
+			t.Errorf("expected: %q, actual: %q, err: %v", expected, actual, err)
+		}
+	})
+
+	t.Run("env prefix", func(t *testing.T) {
+		var buf bytes.Buffer
+		cli := New("cli", "1.0.0", "",
+			OptionStdio(nil, &buf, &buf),
+			OptionAutoCompleter(nopAutoCompleter{}),
+			OptionEnv(lookupEnv(map[string]string{
+				"NAME":     "bare",
+				"CLI_NAME": "env",
+			})),
+			OptionEnvPrefix("cli"),
+		)
+		cli.Add("greet", greetCmdFn)
+
+		_, err := cli.Run([]string{"greet"})
+		if expected, actual := "Hello env!\n", buf.String(); expected != actual {
+			t.Errorf("expected: %q, actual: %q, err: %v", expected, actual, err)
+		}
+	})
+
+	t.Run("env file", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "env")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+
+		path := filepath.Join(dir, ".env")
+		if err := ioutil.WriteFile(path, []byte("NAME=file\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		var buf bytes.Buffer
+		cli := New("cli", "1.0.0", "",
+			OptionStdio(nil, &buf, &buf),
+			OptionAutoCompleter(nopAutoCompleter{}),
+			OptionFileSystem(fsys.NewLocalFileSystem()),
+			OptionEnv(lookupEnv(map[string]string{
+				"ENV_FILE": path,
+			})),
+		)
+		cli.Add("greet", greetCmdFn)
+
+		_, err = cli.Run([]string{"greet"})
+		if expected, actual := "Hello file!\n", buf.String(); expected != actual {
+			t.Errorf("expected: %q, actual: %q, err: %v", expected, actual, err)
+		}
+	})
+}
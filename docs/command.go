@@ -0,0 +1,83 @@
+package docs
+
+import (
+	"context"
+	"flag"
+
+	"github.com/spoke-d/clui/commands"
+	"github.com/spoke-d/clui/flagset"
+	"github.com/spoke-d/task/group"
+)
+
+// GenerateFn is called to generate the pages in the format when the command is
+// run.
+type GenerateFn func(Format) ([]Page, error)
+
+// Command is a command that writes the reference pages for a CLI to a
+// directory.
+type Command struct {
+	flagSet  *flagset.FlagSet
+	generate GenerateFn
+	dir      string
+	html     bool
+}
+
+// NewCommand creates a Command that writes the pages from the generate
+// function.
+func NewCommand(generate GenerateFn) *Command {
+	cmd := &Command{
+		flagSet:  flagset.New("docs", flag.ContinueOnError),
+		generate: generate,
+	}
+	cmd.flagSet.StringVar(&cmd.dir, "dir", ".", "Directory to write the pages to")
+	cmd.flagSet.BoolVar(&cmd.html, "html", false, "Write the pages as HTML instead of Markdown")
+	return cmd
+}
+
+// FlagSet returns the FlagSet associated with the command. All the flags are
+// parsed before running the command.
+func (c *Command) FlagSet() *flagset.FlagSet {
+	return c.flagSet
+}
+
+// Usages returns various usages that can be used for the command.
+func (c *Command) Usages() []string {
+	return make([]string, 0)
+}
+
+// Help should return a long-form help text that includes the command-line
+// usage. A brief few sentences explaining the function of the command, and
+// the complete list of flags the command accepts.
+func (c *Command) Help() string {
+	return `
+Generate the reference pages for every command, along with
+a top level page, and write them to a directory.`
+}
+
+// Synopsis should return a one-line, short synopsis of the command.
+// This should be short (50 characters of less ideally).
+func (c *Command) Synopsis() string {
+	return "Generate reference docs."
+}
+
+// Init is called with all the args required to run a command.
+// This is separated from Run, to allow the preperation of a command, before
+// it's run.
+func (c *Command) Init([]string, commands.CommandContext) error {
+	return nil
+}
+
+// Run subscribes to the group for writing the reference pages.
+func (c *Command) Run(g *group.Group) {
+	g.Add(func(context.Context) error {
+		format := Markdown
+		if c.html {
+			format = HTML
+		}
+		pages, err := c.generate(format)
+		if err != nil {
+			return err
+		}
+		return Write(c.dir, pages)
+	}, commands.Disguard)
+}
@@ -0,0 +1,443 @@
+package docs
+
+import (
+	"bytes"
+	"flag"
+	htmltemplate "html/template"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"github.com/spoke-d/clui/group"
+	"github.com/spoke-d/clui/help"
+	"github.com/spoke-d/clui/radix"
+)
+
+// Format defines the format the reference pages are written in.
+type Format string
+
+const (
+	// Markdown writes the pages as Markdown.
+	Markdown Format = "markdown"
+
+	// HTML writes the pages as static HTML.
+	HTML Format = "html"
+)
+
+// Extension returns the file extension for the format.
+func (f Format) Extension() string {
+	if f == HTML {
+		return ".html"
+	}
+	return ".md"
+}
+
+// Page is a reference page for a command, or the top level page for the CLI.
+type Page struct {
+	// Name of the page, such as "mycli-config-show".
+	Name string
+
+	// Format the content is written in.
+	Format Format
+
+	// Content of the page.
+	Content []byte
+}
+
+// Filename returns the file name of the page, such as "mycli-config-show.md".
+func (p Page) Filename() string {
+	return p.Name + p.Format.Extension()
+}
+
+// ChildrenFn returns the immediate sub commands of the command key. Hidden
+// commands and aliases should not be returned.
+type ChildrenFn func(commands *group.Group, key string) (map[string]group.Command, error)
+
+// DocsOptions represents a way to set optional values to a docs option.
+// The DocsOptions shows what options are available to change.
+type DocsOptions interface {
+	SetFormat(Format)
+	SetDescription(string)
+	SetGlobalFlags([]help.GlobalFlag)
+	SetChildren(ChildrenFn)
+}
+
+// DocsOption captures a tweak that can be applied to the generated pages.
+type DocsOption func(DocsOptions)
+
+type docs struct {
+	format      Format
+	description string
+	globalFlags []help.GlobalFlag
+	children    ChildrenFn
+}
+
+func (s *docs) SetFormat(p Format) {
+	s.format = p
+}
+
+func (s *docs) SetDescription(p string) {
+	s.description = p
+}
+
+func (s *docs) SetGlobalFlags(p []help.GlobalFlag) {
+	s.globalFlags = p
+}
+
+func (s *docs) SetChildren(p ChildrenFn) {
+	s.children = p
+}
+
+// OptionFormat allows the setting a format option to configure the pages.
+// The format is Markdown by default.
+func OptionFormat(i Format) DocsOption {
+	return func(opt DocsOptions) {
+		opt.SetFormat(i)
+	}
+}
+
+// OptionDescription allows the setting a description option to configure the
+// description on the top level page.
+func OptionDescription(i string) DocsOption {
+	return func(opt DocsOptions) {
+		opt.SetDescription(i)
+	}
+}
+
+// OptionGlobalFlags allows the setting of the global flags that are
+// documented on every page.
+func OptionGlobalFlags(i []help.GlobalFlag) DocsOption {
+	return func(opt DocsOptions) {
+		opt.SetGlobalFlags(i)
+	}
+}
+
+// OptionChildren allows the setting of the function used to find the sub
+// commands of a command, for linking the pages together.
+func OptionChildren(i ChildrenFn) DocsOption {
+	return func(opt DocsOptions) {
+		opt.SetChildren(i)
+	}
+}
+
+// Generate walks the commands and creates a page for every command, along
+// with a top level page for the CLI. Hidden commands and aliases are skipped.
+// The output only depends on the commands, so it can be checked in and
+// diffed.
+func Generate(name string, commands *group.Group, options ...DocsOption) ([]Page, error) {
+	opt := &docs{
+		format:   Markdown,
+		children: children,
+	}
+	for _, option := range options {
+		option(opt)
+	}
+
+	render, err := renderer(opt.format)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	commands.WalkPrefix("", func(key string, _ radix.Value) bool {
+		if !commands.Hidden(key) && !commands.IsAlias(key) {
+			keys = append(keys, key)
+		}
+		return false
+	})
+	sort.Strings(keys)
+
+	g := &generator{
+		name:     name,
+		opt:      opt,
+		commands: commands,
+	}
+
+	root, err := g.root()
+	if err != nil {
+		return nil, err
+	}
+	views := []view{root}
+	for _, key := range keys {
+		cmd, ok := commands.Get(key)
+		if !ok {
+			return nil, errors.Errorf("command %q not found", key)
+		}
+		v, err := g.command(key, cmd)
+		if err != nil {
+			return nil, err
+		}
+		views = append(views, v)
+	}
+
+	pages := make([]Page, len(views))
+	for i, v := range views {
+		content, err := render(v)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		pages[i] = Page{
+			Name:    v.Page,
+			Format:  opt.format,
+			Content: content,
+		}
+	}
+	return pages, nil
+}
+
+// Write writes the pages to the directory, creating the directory if it
+// doesn't exist.
+func Write(dir string, pages []Page) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return errors.WithStack(err)
+	}
+	for _, page := range pages {
+		path := filepath.Join(dir, page.Filename())
+		if err := ioutil.WriteFile(path, page.Content, 0644); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+// view is the data used to render a page.
+type view struct {
+	Name           string
+	Page           string
+	Synopsis       string
+	Description    []string
+	Usages         []string
+	Flags          []flagView
+	InheritedFlags []flagView
+	GlobalFlags    []flagView
+	Aliases        []string
+	Deprecated     string
+	Commands       []link
+	Parent         *link
+}
+
+type flagView struct {
+	Name    string
+	Short   string
+	Type    string
+	Default string
+	Usage   string
+}
+
+type link struct {
+	Name     string
+	Href     string
+	Synopsis string
+}
+
+type generator struct {
+	name     string
+	opt      *docs
+	commands *group.Group
+}
+
+func (g *generator) root() (view, error) {
+	children, err := g.links("")
+	if err != nil {
+		return view{}, err
+	}
+
+	description := paragraphs(g.opt.description)
+	var synopsis string
+	if len(description) > 0 {
+		synopsis = firstLine(description[0])
+	}
+
+	return view{
+		Name:        g.name,
+		Page:        g.pageName(""),
+		Synopsis:    synopsis,
+		Description: description,
+		Usages:      []string{"[global flags] <command> [<args>]"},
+		GlobalFlags: g.globalFlags(),
+		Commands:    children,
+	}, nil
+}
+
+func (g *generator) command(key string, cmd group.Command) (view, error) {
+	children, err := g.links(key)
+	if err != nil {
+		return view{}, err
+	}
+
+	full := g.name + " " + key
+	v := view{
+		Name:        full,
+		Page:        g.pageName(key),
+		Synopsis:    cmd.Synopsis(),
+		Description: paragraphs(cmd.Help()),
+		GlobalFlags: g.globalFlags(),
+		Commands:    children,
+	}
+
+	flagSet := cmd.FlagSet()
+	flagSet.VisitAll(func(f *flag.Flag) {
+		_, usage := flag.UnquoteUsage(f)
+		fv := flagView{
+			Name:    f.Name,
+			Short:   flagSet.Short(f.Name),
+			Type:    flagSet.Type(f.Name),
+			Default: f.DefValue,
+			Usage:   usage,
+		}
+		if flagSet.Inherited(f.Name) {
+			v.InheritedFlags = append(v.InheritedFlags, fv)
+		} else {
+			v.Flags = append(v.Flags, fv)
+		}
+	})
+
+	if len(v.Flags)+len(v.InheritedFlags) > 0 {
+		v.Usages = append(v.Usages, "[flags]")
+	}
+	v.Usages = append(v.Usages, cmd.Usages()...)
+
+	for _, alias := range g.commands.Aliases(key) {
+		v.Aliases = append(v.Aliases, g.name+" "+alias)
+	}
+	if replacement, ok := g.commands.Deprecated(key); ok {
+		v.Deprecated = g.name + " " + replacement
+	}
+
+	parent := &link{
+		Name:     g.name,
+		Href:     g.pageName("") + g.opt.format.Extension(),
+		Synopsis: firstLine(g.opt.description),
+	}
+	if idx := strings.LastIndex(key, " "); idx >= 0 {
+		if cmd, ok := g.commands.Get(key[:idx]); ok {
+			parent = &link{
+				Name:     g.name + " " + key[:idx],
+				Href:     g.pageName(key[:idx]) + g.opt.format.Extension(),
+				Synopsis: cmd.Synopsis(),
+			}
+		}
+	}
+	v.Parent = parent
+
+	return v, nil
+}
+
+// links returns the links to the pages of the sub commands of the key,
+// sorted by name.
+func (g *generator) links(key string) ([]link, error) {
+	children, err := g.opt.children(g.commands, key)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(children))
+	for k := range children {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	links := make([]link, len(keys))
+	for i, k := range keys {
+		links[i] = link{
+			Name:     g.name + " " + k,
+			Href:     g.pageName(k) + g.opt.format.Extension(),
+			Synopsis: children[k].Synopsis(),
+		}
+	}
+	return links, nil
+}
+
+func (g *generator) globalFlags() []flagView {
+	flags := make([]flagView, len(g.opt.globalFlags))
+	for i, f := range g.opt.globalFlags {
+		flags[i] = flagView{
+			Name:  f.Name,
+			Short: f.Short,
+			Usage: f.Usage,
+		}
+	}
+	return flags
+}
+
+func (g *generator) pageName(key string) string {
+	return strings.Join(append([]string{g.name}, strings.Fields(key)...), "-")
+}
+
+// children returns the immediate sub commands of the key, without the hidden
+// commands or aliases.
+func children(commands *group.Group, key string) (map[string]group.Command, error) {
+	prefix := key
+	if prefix != "" {
+		prefix += " "
+	}
+
+	res := make(map[string]group.Command)
+	var err error
+	commands.WalkPrefix(prefix, func(k string, _ radix.Value) bool {
+		if strings.Contains(k[len(prefix):], " ") || commands.IsAlias(k) || commands.Hidden(k) {
+			return false
+		}
+		cmd, ok := commands.Get(k)
+		if !ok {
+			err = errors.Errorf("not found: %q", k)
+			return true
+		}
+		res[k] = cmd
+		return false
+	})
+	return res, err
+}
+
+func renderer(format Format) (func(view) ([]byte, error), error) {
+	switch format {
+	case Markdown:
+		tmpl := template.Must(template.New("markdown").Funcs(template.FuncMap{
+			"cell": cell,
+		}).Parse(MarkdownTemplate))
+		return func(v view) ([]byte, error) {
+			var buf bytes.Buffer
+			err := tmpl.Execute(&buf, v)
+			return buf.Bytes(), err
+		}, nil
+	case HTML:
+		tmpl := htmltemplate.Must(htmltemplate.New("html").Parse(HTMLTemplate))
+		return func(v view) ([]byte, error) {
+			var buf bytes.Buffer
+			err := tmpl.Execute(&buf, v)
+			return buf.Bytes(), err
+		}, nil
+	}
+	return nil, errors.Errorf("unknown docs format %q", format)
+}
+
+// cell escapes the text, so that it can be written in a Markdown table cell.
+func cell(text string) string {
+	text = strings.Replace(text, "|", `\|`, -1)
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// paragraphs splits the text into paragraphs on blank lines.
+func paragraphs(text string) []string {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+	var res []string
+	for _, paragraph := range strings.Split(text, "\n\n") {
+		var lines []string
+		for _, line := range strings.Split(strings.TrimSpace(paragraph), "\n") {
+			lines = append(lines, strings.TrimSpace(line))
+		}
+		res = append(res, strings.Join(lines, "\n"))
+	}
+	return res
+}
+
+func firstLine(text string) string {
+	return strings.TrimSpace(strings.SplitN(strings.TrimSpace(text), "\n", 2)[0])
+}
@@ -0,0 +1,197 @@
+package docs
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spoke-d/clui/commands"
+	"github.com/spoke-d/clui/flagset"
+	"github.com/spoke-d/clui/group"
+	"github.com/spoke-d/clui/help"
+	task "github.com/spoke-d/task/group"
+)
+
+type stubCommand struct {
+	flagSet  *flagset.FlagSet
+	usages   []string
+	help     string
+	synopsis string
+}
+
+func newStubCommand(synopsis, help string, usages ...string) *stubCommand {
+	return &stubCommand{
+		flagSet:  flagset.New(synopsis, flag.ContinueOnError),
+		usages:   usages,
+		help:     help,
+		synopsis: synopsis,
+	}
+}
+
+func (c *stubCommand) FlagSet() *flagset.FlagSet                    { return c.flagSet }
+func (c *stubCommand) Usages() []string                             { return c.usages }
+func (c *stubCommand) Help() string                                 { return c.help }
+func (c *stubCommand) Synopsis() string                             { return c.synopsis }
+func (c *stubCommand) Init([]string, commands.CommandContext) error { return nil }
+func (c *stubCommand) Run(g *task.Group)                            { commands.Nothing(g) }
+
+func newGroup(t *testing.T) *group.Group {
+	config := newStubCommand("Manage configuration.", "Manage the configuration.")
+	config.FlagSet().Persistent().String("server", "localhost", "Server to `address`")
+
+	show := newStubCommand("Show configuration.", `
+Show the current configuration.
+
+Use --server to pick another server.`, "<key>")
+	show.FlagSet().Bool("all", false, "Show all keys | values")
+
+	g := group.New()
+	for _, c := range []struct {
+		key     string
+		cmd     group.Command
+		options []group.CommandOption
+	}{
+		{key: "config", cmd: config},
+		{key: "config show", cmd: show, options: []group.CommandOption{group.OptionAliases("get")}},
+		{key: "secret", cmd: newStubCommand("Secret.", ""), options: []group.CommandOption{group.OptionHidden()}},
+	} {
+		if err := g.Add(c.key, c.cmd, c.options...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := g.Process(); err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	globalFlags := OptionGlobalFlags([]help.GlobalFlag{
+		{Name: "help", Short: "h", Usage: "Print command help"},
+	})
+
+	t.Run("pages", func(t *testing.T) {
+		pages, err := Generate("cli", newGroup(t))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var names []string
+		for _, page := range pages {
+			names = append(names, page.Filename())
+		}
+		if expected, actual := []string{"cli.md", "cli-config.md", "cli-config-show.md"}, names; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("root", func(t *testing.T) {
+		pages, err := Generate("cli", newGroup(t), OptionDescription("A command line interface."), globalFlags)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := "# cli\n\nA command line interface.\n\n## Usage\n\n```\ncli [global flags] <command> [<args>]\n```\n\n" +
+			"## Description\n\nA command line interface.\n\n" +
+			"## Global Flags\n\n| Flag | Description |\n| --- | --- |\n| `-h`, `--help` | Print command help |\n\n" +
+			"## Commands\n\n| Command | Description |\n| --- | --- |\n| [cli config](cli-config.md) | Manage configuration. |\n"
+		if actual := string(pages[0].Content); expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+	})
+
+	t.Run("command", func(t *testing.T) {
+		pages, err := Generate("cli", newGroup(t))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := "# cli config show\n\nShow configuration.\n\n## Usage\n\n```\ncli config show [flags]\ncli config show <key>\n```\n\n" +
+			"## Description\n\nShow the current configuration.\n\nUse --server to pick another server.\n\n" +
+			"## Flags\n\n| Flag | Type | Default | Description |\n| --- | --- | --- | --- |\n| `--all` |  | `false` | Show all keys \\| values |\n\n" +
+			"## Inherited Flags\n\n| Flag | Type | Default | Description |\n| --- | --- | --- | --- |\n| `--server` | address | `localhost` | Server to address |\n\n" +
+			"## Aliases\n\n- `cli config get`\n\n" +
+			"## See Also\n\n- [cli config](cli-config.md) - Manage configuration.\n"
+		if actual := string(pages[2].Content); expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+	})
+
+	t.Run("html", func(t *testing.T) {
+		pages, err := Generate("cli", newGroup(t), OptionFormat(HTML), globalFlags)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if expected, actual := "cli-config.html", pages[1].Filename(); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		content := string(pages[1].Content)
+		for _, expected := range []string{
+			"<h1>cli config</h1>",
+			`<tr><td><a href="cli-config-show.html">cli config show</a></td><td>Show configuration.</td></tr>`,
+			`<li><a href="cli.html">cli</a></li>`,
+			"<tr><td><code>-h</code>, <code>--help</code></td><td>Print command help</td></tr>",
+		} {
+			if !strings.Contains(content, expected) {
+				t.Errorf("expected: %q in %q", expected, content)
+			}
+		}
+	})
+
+	t.Run("deterministic", func(t *testing.T) {
+		a, err := Generate("cli", newGroup(t))
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := Generate("cli", newGroup(t))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := a, b; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+}
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "docs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var format Format
+	cmd := NewCommand(func(f Format) ([]Page, error) {
+		format = f
+		return []Page{{Name: "cli", Format: f, Content: []byte("<h1>cli</h1>\n")}}, nil
+	})
+	if err := cmd.FlagSet().Parse([]string{"--dir", dir, "--html"}); err != nil {
+		t.Fatal(err)
+	}
+
+	g := task.NewGroup()
+	cmd.Run(g)
+	if err := g.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected, actual := HTML, format; expected != actual {
+		t.Errorf("expected: %v, actual: %v", expected, actual)
+	}
+	content, err := ioutil.ReadFile(filepath.Join(dir, "cli.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected, actual := "<h1>cli</h1>\n", string(content); expected != actual {
+		t.Errorf("expected: %v, actual: %v", expected, actual)
+	}
+}
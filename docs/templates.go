@@ -0,0 +1,166 @@
+package docs
+
+// MarkdownTemplate is the template used for rendering a page as Markdown.
+const MarkdownTemplate = `# {{.Name}}
+{{- if .Synopsis}}
+
+{{.Synopsis}}
+{{- end}}
+
+## Usage
+
+` + "```" + `
+{{- range .Usages}}
+{{$.Name}} {{.}}
+{{- end}}
+` + "```" + `
+{{- if .Description}}
+
+## Description
+{{- range .Description}}
+
+{{.}}
+{{- end}}
+{{- end}}
+{{- if .Flags}}
+
+## Flags
+
+| Flag | Type | Default | Description |
+| --- | --- | --- | --- |
+{{- range .Flags}}
+| {{if .Short}}` + "`-{{.Short}}`" + `, {{end}}` + "`--{{.Name}}`" + ` | {{cell .Type}} | {{if .Default}}` + "`{{.Default}}`" + `{{end}} | {{cell .Usage}} |
+{{- end}}
+{{- end}}
+{{- if .InheritedFlags}}
+
+## Inherited Flags
+
+| Flag | Type | Default | Description |
+| --- | --- | --- | --- |
+{{- range .InheritedFlags}}
+| {{if .Short}}` + "`-{{.Short}}`" + `, {{end}}` + "`--{{.Name}}`" + ` | {{cell .Type}} | {{if .Default}}` + "`{{.Default}}`" + `{{end}} | {{cell .Usage}} |
+{{- end}}
+{{- end}}
+{{- if .GlobalFlags}}
+
+## Global Flags
+
+| Flag | Description |
+| --- | --- |
+{{- range .GlobalFlags}}
+| {{if .Short}}` + "`-{{.Short}}`" + `, {{end}}` + "`--{{.Name}}`" + ` | {{cell .Usage}} |
+{{- end}}
+{{- end}}
+{{- if .Aliases}}
+
+## Aliases
+{{range .Aliases}}
+- ` + "`{{.}}`" + `
+{{- end}}
+{{- end}}
+{{- if .Deprecated}}
+
+## Deprecated
+
+Use ` + "`{{.Deprecated}}`" + ` instead.
+{{- end}}
+{{- if .Commands}}
+
+## Commands
+
+| Command | Description |
+| --- | --- |
+{{- range .Commands}}
+| [{{.Name}}]({{.Href}}) | {{cell .Synopsis}} |
+{{- end}}
+{{- end}}
+{{- with .Parent}}
+
+## See Also
+
+- [{{.Name}}]({{.Href}}){{if .Synopsis}} - {{.Synopsis}}{{end}}
+{{- end}}
+`
+
+// HTMLTemplate is the template used for rendering a page as static HTML.
+const HTMLTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Name}}</title>
+</head>
+<body>
+<h1>{{.Name}}</h1>
+{{- if .Synopsis}}
+<p>{{.Synopsis}}</p>
+{{- end}}
+<h2>Usage</h2>
+<pre>
+{{- range .Usages}}
+{{$.Name}} {{.}}
+{{- end}}
+</pre>
+{{- if .Description}}
+<h2>Description</h2>
+{{- range .Description}}
+<p>{{.}}</p>
+{{- end}}
+{{- end}}
+{{- if .Flags}}
+<h2>Flags</h2>
+<table>
+<tr><th>Flag</th><th>Type</th><th>Default</th><th>Description</th></tr>
+{{- range .Flags}}
+<tr><td>{{if .Short}}<code>-{{.Short}}</code>, {{end}}<code>--{{.Name}}</code></td><td>{{.Type}}</td><td>{{if .Default}}<code>{{.Default}}</code>{{end}}</td><td>{{.Usage}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .InheritedFlags}}
+<h2>Inherited Flags</h2>
+<table>
+<tr><th>Flag</th><th>Type</th><th>Default</th><th>Description</th></tr>
+{{- range .InheritedFlags}}
+<tr><td>{{if .Short}}<code>-{{.Short}}</code>, {{end}}<code>--{{.Name}}</code></td><td>{{.Type}}</td><td>{{if .Default}}<code>{{.Default}}</code>{{end}}</td><td>{{.Usage}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .GlobalFlags}}
+<h2>Global Flags</h2>
+<table>
+<tr><th>Flag</th><th>Description</th></tr>
+{{- range .GlobalFlags}}
+<tr><td>{{if .Short}}<code>-{{.Short}}</code>, {{end}}<code>--{{.Name}}</code></td><td>{{.Usage}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Aliases}}
+<h2>Aliases</h2>
+<ul>
+{{- range .Aliases}}
+<li><code>{{.}}</code></li>
+{{- end}}
+</ul>
+{{- end}}
+{{- if .Deprecated}}
+<h2>Deprecated</h2>
+<p>Use <code>{{.Deprecated}}</code> instead.</p>
+{{- end}}
+{{- if .Commands}}
+<h2>Commands</h2>
+<table>
+<tr><th>Command</th><th>Description</th></tr>
+{{- range .Commands}}
+<tr><td><a href="{{.Href}}">{{.Name}}</a></td><td>{{.Synopsis}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- with .Parent}}
+<h2>See Also</h2>
+<ul>
+<li><a href="{{.Href}}">{{.Name}}</a>{{if .Synopsis}} - {{.Synopsis}}{{end}}</li>
+</ul>
+{{- end}}
+</body>
+</html>
+`
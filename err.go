@@ -1,5 +1,13 @@
 package clui
 
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spoke-d/clui/plugin"
+)
+
 // Errno represents a error constants that can be reutrned from the CLI
 type Errno int
 
@@ -18,3 +26,105 @@ const (
 	// EKeyExpired is outside of POSIX 1, represents unknown error.
 	EKeyExpired Errno = 127
 )
+
+// ExitCoder is an error that carries an explicit exit code. Commands can
+// return an ExitCoder from Init or Run to select the exit code that the CLI
+// returns.
+type ExitCoder interface {
+	error
+
+	// Code returns the exit code for the error.
+	Code() int
+}
+
+// ExitError is an error that carries an exit code.
+type ExitError struct {
+	err   error
+	errno Errno
+}
+
+// NewExitError creates an ExitError for the given error and exit code.
+func NewExitError(err error, errno Errno) *ExitError {
+	return &ExitError{
+		err:   err,
+		errno: errno,
+	}
+}
+
+func (e *ExitError) Error() string {
+	if e.err == nil {
+		return fmt.Sprintf("exit code %d", e.errno)
+	}
+	return e.err.Error()
+}
+
+// Code returns the exit code of the error.
+func (e *ExitError) Code() int {
+	return e.errno.Code()
+}
+
+// Cause returns the underlying cause of the error.
+func (e *ExitError) Cause() error {
+	return e.err
+}
+
+// Unwrap returns the underlying error.
+func (e *ExitError) Unwrap() error {
+	return e.err
+}
+
+// exitCode attempts to find an explicit exit code within the error chain.
+// Returns true if the exit code was found.
+func exitCode(err error) (Errno, bool) {
+	var e ExitCoder
+	if errors.As(err, &e) {
+		return Errno(e.Code()), true
+	}
+	return EOK, false
+}
+
+// reported returns if the error has already been reported, so only the exit
+// code is passed on. Plugins report their own errors, and an ExitError without
+// an error has nothing else to report.
+func reported(err error) bool {
+	var pluginErr *plugin.ExitError
+	if errors.As(err, &pluginErr) {
+		return true
+	}
+	var exitErr *ExitError
+	return errors.As(err, &exitErr) && exitErr.err == nil
+}
+
+// errorCauses returns the messages of all the errors that are wrapped by the
+// error. Messages that don't add anything to the error they wrap are skipped.
+func errorCauses(err error) []string {
+	var causes []string
+	for prev := err.Error(); err != nil; err = errors.Unwrap(err) {
+		if msg := err.Error(); msg != prev {
+			causes = append(causes, msg)
+			prev = msg
+		}
+	}
+	return causes
+}
+
+// errorStack returns the stack trace of the inner most error that recorded
+// one.
+func errorStack(err error) string {
+	type stackTracer interface {
+		StackTrace() errors.StackTrace
+	}
+
+	var stack errors.StackTrace
+	for ; err != nil; err = errors.Unwrap(err) {
+		if e, ok := err.(stackTracer); ok {
+			stack = e.StackTrace()
+		}
+	}
+	if stack == nil {
+		return ""
+	}
+	// Tabs are replaced, as the help output aligns on them.
+	trace := strings.TrimSpace(fmt.Sprintf("%+v", stack))
+	return strings.Replace(trace, "\t", "    ", -1)
+}
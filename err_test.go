@@ -0,0 +1,205 @@
+package clui
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/spoke-d/clui/commands"
+	"github.com/spoke-d/clui/flagset"
+	"github.com/spoke-d/clui/plugin"
+	"github.com/spoke-d/clui/ui"
+)
+
+func TestExitCode(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no exit code", func(t *testing.T) {
+		_, ok := exitCode(errors.New("bad"))
+		if expected, actual := false, ok; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("exit code", func(t *testing.T) {
+		code, ok := exitCode(NewExitError(errors.New("bad"), Errno(3)))
+		if expected, actual := true, ok; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := Errno(3), code; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("wrapped exit code", func(t *testing.T) {
+		err := errors.Wrap(NewExitError(errors.New("bad"), Errno(4)), "conflict")
+		code, ok := exitCode(err)
+		if expected, actual := true, ok; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := Errno(4), code; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+}
+
+func TestReported(t *testing.T) {
+	t.Parallel()
+
+	t.Run("error", func(t *testing.T) {
+		if expected, actual := false, reported(NewExitError(errors.New("bad"), Errno(3))); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("exit code only", func(t *testing.T) {
+		err := NewExitError(nil, Errno(3))
+		if expected, actual := true, reported(errors.WithStack(err)); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := "exit code 3", err.Error(); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("plugin", func(t *testing.T) {
+		if expected, actual := true, reported(errors.WithStack(&plugin.ExitError{})); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+}
+
+func TestErrorCauses(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no causes", func(t *testing.T) {
+		causes := errorCauses(errors.New("bad"))
+		if expected, actual := 0, len(causes); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("causes", func(t *testing.T) {
+		err := errors.Wrap(errors.Wrap(errors.New("timeout"), "dial"), "request")
+		causes := errorCauses(err)
+		if expected, actual := []string{"dial: timeout", "timeout"}, causes; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+}
+
+func TestErrorStack(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no stack", func(t *testing.T) {
+		if expected, actual := "", errorStack(fmt.Errorf("bad")); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("stack", func(t *testing.T) {
+		stack := errorStack(errors.Wrap(errors.New("bad"), "worse"))
+		if expected, actual := true, strings.Contains(stack, "TestErrorStack"); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+}
+
+func TestRunExitCode(t *testing.T) {
+	t.Parallel()
+
+	t.Run("init", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		cmd := NewMockCommand(ctrl)
+		cmd.EXPECT().FlagSet().Return(flagset.New("foo", flag.ContinueOnError))
+		cmd.EXPECT().Init(gomock.Any(), gomock.Any()).Return(NewExitError(errors.New("not found"), Errno(3)))
+
+		var stdout, stderr bytes.Buffer
+		cli := New("cli", "1.0.0", "",
+			OptionUI(ui.NewBasicUI(nil, &stdout, &stderr)),
+			OptionAutoCompleter(nopAutoCompleter{}),
+		)
+		cli.Add("foo", func(UI) Command { return cmd })
+
+		code, err := cli.Run([]string{"foo", "--no-color"})
+		if expected, actual := true, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v, err: %v", expected, actual, err)
+		}
+		if expected, actual := Errno(3), code; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := "", stdout.String(); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := "Error: not found\n", stderr.String(); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("runtime error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		cmd := NewMockCommand(ctrl)
+		cmd.EXPECT().FlagSet().Return(flagset.New("foo", flag.ContinueOnError))
+		cmd.EXPECT().Init(gomock.Any(), gomock.Any()).Return(errors.Wrap(errors.New("timeout"), "dial"))
+
+		var stdout, stderr bytes.Buffer
+		cli := New("cli", "1.0.0", "",
+			OptionUI(ui.NewBasicUI(nil, &stdout, &stderr)),
+			OptionAutoCompleter(nopAutoCompleter{}),
+		)
+		cli.Add("foo", func(UI) Command { return cmd })
+
+		code, err := cli.Run([]string{"foo", "--no-color", "--debug"})
+		if expected, actual := true, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v, err: %v", expected, actual, err)
+		}
+		if expected, actual := EPerm, code; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := "", stdout.String(); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		prefix := "Error: dial: timeout\n\nCaused by:\n\n    timeout\n\nStack trace:\n"
+		if expected, actual := true, strings.HasPrefix(stderr.String(), prefix); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, stderr.String())
+		}
+	})
+
+	t.Run("usage error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		cmd := NewMockCommand(ctrl)
+		cmd.EXPECT().FlagSet().Return(flagset.New("foo", flag.ContinueOnError)).Times(2)
+		cmd.EXPECT().Init(gomock.Any(), gomock.Any()).Return(commands.Usagef("missing name"))
+		cmd.EXPECT().Help().Return("")
+		cmd.EXPECT().Usages().Return(nil)
+
+		var stdout, stderr bytes.Buffer
+		cli := New("cli", "1.0.0", "",
+			OptionUI(ui.NewBasicUI(nil, &stdout, &stderr)),
+			OptionAutoCompleter(nopAutoCompleter{}),
+		)
+		cli.Add("foo", func(UI) Command { return cmd })
+
+		code, err := cli.Run([]string{"foo", "--no-color"})
+		if expected, actual := true, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v, err: %v", expected, actual, err)
+		}
+		if expected, actual := EPerm, code; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := true, strings.Contains(stdout.String(), "missing name"); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+}
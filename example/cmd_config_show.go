@@ -31,6 +31,7 @@ func configShowCmdFn(ui clui.UI) clui.Command {
 func (v *configShowCmd) init() {
 	v.flagSet.StringVar(&v.template, "template", "{{.Key}}	{{.Value}}", "Template for show key and values")
 	v.flagSet.BoolVar(&v.test, "test", false, "test")
+	v.flagSet.Persistent().BoolVar(&v.server, "server", false, "Request server configuration")
 }
 
 func (v *configShowCmd) FlagSet() *flagset.FlagSet {
@@ -11,12 +11,17 @@ import (
 func main() {
 	fsys := fsys.NewLocalFileSystem()
 
-	cli := clui.New("example", "1.0.0", "EXAMPLE", clui.OptionFileSystem(fsys))
+	cli := clui.New("example", "1.0.0", "EXAMPLE",
+		clui.OptionFileSystem(fsys),
+		clui.OptionPlugins(),
+		clui.OptionManCommand("man"),
+		clui.OptionDocsCommand("docs"),
+	)
 	cli.Add("version", versionCmdFn)
 	cli.Add("config show", configShowCmdFn)
 	cli.Add("config show something", configShowCmdFn)
 	cli.Add("config show else", configShowCmdFn)
-	cli.Add("config list", configShowCmdFn)
+	cli.Add("config list", configShowCmdFn, clui.OptionAliases("ls"))
 
 	code, err := cli.Run(os.Args[1:])
 	if err != nil {
@@ -9,7 +9,8 @@ import (
 )
 
 // FindChildren returns the sub commands.
-// This will only contain immediate sub commands.
+// This will only contain immediate sub commands. Aliases of commands are not
+// included, nor are any hidden commands.
 func FindChildren(commands *group.Group, prefix string, includeSubKeys bool) (map[string]Command, error) {
 	// if our prefix isn't empty, make sure it ends in ' '
 	if prefix != "" && prefix[len(prefix)-1] != ' ' {
@@ -23,6 +24,10 @@ func FindChildren(commands *group.Group, prefix string, includeSubKeys bool) (ma
 		if !includeSubKeys && strings.Contains(k[len(prefix):], " ") {
 			return false
 		}
+		// Aliases point to the same command, so ignore them.
+		if commands.IsAlias(k) || commands.Hidden(k) {
+			return false
+		}
 
 		keys = append(keys, k)
 
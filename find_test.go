@@ -79,4 +79,41 @@ func TestFindChildren(t *testing.T) {
 			t.Errorf("expected: %v, actual: %v", expected, actual)
 		}
 	})
+
+	t.Run("group with aliases", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		cmd := NewMockCommand(ctrl)
+
+		group := group.New()
+		group.Add("foo bar", cmd, OptionAliases("baz"))
+
+		children, err := FindChildren(group, "foo", false)
+		if expected, actual := true, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v, err: %v", expected, actual, err)
+		}
+		if expected, actual := map[string]Command{"foo bar": cmd}, children; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("group with hidden", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		cmd := NewMockCommand(ctrl)
+
+		group := group.New()
+		group.Add("foo bar", cmd)
+		group.Add("foo baz", cmd, OptionHidden())
+
+		children, err := FindChildren(group, "foo", false)
+		if expected, actual := true, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v, err: %v", expected, actual, err)
+		}
+		if expected, actual := map[string]Command{"foo bar": cmd}, children; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
 }
@@ -0,0 +1,138 @@
+package flagset
+
+import (
+	"fmt"
+	"strings"
+)
+
+// constraintKind describes how the flags in a constraint relate to each other.
+type constraintKind int
+
+const (
+	constraintRequired constraintKind = iota
+	constraintExclusive
+	constraintTogether
+)
+
+type constraint struct {
+	kind  constraintKind
+	names []string
+}
+
+// MarkRequired marks the named flags as required. A required flag must be
+// set, either as a flag, from the environment or from the config file.
+func (f *FlagSet) MarkRequired(names ...string) {
+	for _, name := range names {
+		f.constraints = append(f.constraints, constraint{
+			kind:  constraintRequired,
+			names: []string{name},
+		})
+	}
+}
+
+// MarkExclusive marks the named flags as mutually exclusive, so only one of
+// them can be set at a time.
+func (f *FlagSet) MarkExclusive(names ...string) {
+	f.constraints = append(f.constraints, constraint{
+		kind:  constraintExclusive,
+		names: names,
+	})
+}
+
+// MarkRequiredTogether marks the named flags as required together, so if one
+// of them is set, then all of them must be set.
+func (f *FlagSet) MarkRequiredTogether(names ...string) {
+	f.constraints = append(f.constraints, constraint{
+		kind:  constraintTogether,
+		names: names,
+	})
+}
+
+// Required returns if the named flag has been marked as required.
+func (f *FlagSet) Required(name string) bool {
+	for _, c := range f.allConstraints() {
+		if c.kind == constraintRequired && c.names[0] == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the flags that have been parsed against the constraints.
+// Returns a ConstraintError with every violation, if any of the constraints
+// aren't met.
+func (f *FlagSet) Validate() error {
+	var violations []string
+	for _, c := range f.allConstraints() {
+		var set, unset []string
+		for _, name := range c.names {
+			if f.Lookup(name) == nil {
+				continue
+			}
+			if f.Origin(name) == OriginDefault {
+				unset = append(unset, flagName(name))
+			} else {
+				set = append(set, flagName(name))
+			}
+		}
+
+		switch c.kind {
+		case constraintRequired:
+			if len(unset) > 0 {
+				violations = append(violations, fmt.Sprintf("required flag %s not set", unset[0]))
+			}
+		case constraintExclusive:
+			if len(set) > 1 {
+				violations = append(violations, fmt.Sprintf("flags %s can not be used together", strings.Join(set, ", ")))
+			}
+		case constraintTogether:
+			if len(set) > 0 && len(unset) > 0 {
+				names := make([]string, len(c.names))
+				for i, name := range c.names {
+					names[i] = flagName(name)
+				}
+				violations = append(violations, fmt.Sprintf("flags %s must be used together, missing %s",
+					strings.Join(names, ", "),
+					strings.Join(unset, ", "),
+				))
+			}
+		}
+	}
+	if len(violations) > 0 {
+		return &ConstraintError{
+			Violations: violations,
+		}
+	}
+	return nil
+}
+
+func (f *FlagSet) allConstraints() []constraint {
+	constraints := f.constraints
+	if f.persistent != nil {
+		constraints = append(constraints[:len(constraints):len(constraints)], f.persistent.constraints...)
+	}
+	return constraints
+}
+
+func (f *FlagSet) hasConstraint(c constraint) bool {
+	for _, other := range f.constraints {
+		if other.kind == c.kind && strings.Join(other.names, " ") == strings.Join(c.names, " ") {
+			return true
+		}
+	}
+	return false
+}
+
+// ConstraintError is returned when the parsed flags don't meet the required,
+// exclusive or required together constraints of the FlagSet.
+type ConstraintError struct {
+	Violations []string
+}
+
+func (e *ConstraintError) Error() string {
+	return strings.Join(e.Violations, "\n")
+}
+
+func flagName(name string) string {
+	return fmt.Sprintf("--%s", name)
+}
@@ -9,6 +9,9 @@ import (
 	"strings"
 	"syscall"
 	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spoke-d/clui/autocomplete/args"
 )
 
 // A FlagSet represents a set of defined flags. The zero value of a FlagSet
@@ -17,13 +20,58 @@ type FlagSet struct {
 	flag             *flag.FlagSet
 	Usage            func()
 	src, args, flags []string
+
+	persistent  *FlagSet
+	inherited   map[string]struct{}
+	config      map[string]string
+	origins     map[string]Origin
+	lookupEnv   func(string) (string, bool)
+	envPrefix   string
+	readFile    func(string) ([]byte, error)
+	predictors  map[string]args.Predictor
+	shorts      map[string]string
+	constraints []constraint
+}
+
+// Origin describes where the value of a flag came from.
+type Origin int
+
+const (
+	// OriginDefault is used when the flag has its default value.
+	OriginDefault Origin = iota
+	// OriginConfig is used when the value came from a config file.
+	OriginConfig
+	// OriginEnv is used when the value came from an environment variable, or
+	// the ENV_FILE.
+	OriginEnv
+	// OriginFlag is used when the value was passed as a flag.
+	OriginFlag
+)
+
+func (o Origin) String() string {
+	switch o {
+	case OriginConfig:
+		return "config"
+	case OriginEnv:
+		return "env"
+	case OriginFlag:
+		return "flag"
+	default:
+		return "default"
+	}
 }
 
 // New returns a new, empty flag set with the specified name and error
 // handling property.
 func New(name string, errorHandling flag.ErrorHandling) *FlagSet {
 	flag := &FlagSet{
-		flag: flag.NewFlagSet(name, errorHandling),
+		flag:       flag.NewFlagSet(name, errorHandling),
+		inherited:  make(map[string]struct{}),
+		origins:    make(map[string]Origin),
+		lookupEnv:  syscall.Getenv,
+		readFile:   ioutil.ReadFile,
+		predictors: make(map[string]args.Predictor),
+		shorts:     make(map[string]string),
 	}
 	flag.SetOutput(ioutil.Discard)
 	return flag
@@ -35,33 +83,195 @@ func (f *FlagSet) SetOutput(output io.Writer) {
 	f.flag.SetOutput(output)
 }
 
+// Persistent returns the FlagSet of persistent flags. Persistent flags are
+// parsed like any other flag, but are also inherited by every nested sub
+// command.
+func (f *FlagSet) Persistent() *FlagSet {
+	if f.persistent == nil {
+		f.persistent = New(f.flag.Name(), flag.ContinueOnError)
+	}
+	return f.persistent
+}
+
+// HasPersistent returns if any persistent flags have been defined.
+func (f *FlagSet) HasPersistent() bool {
+	return f.persistent != nil && f.persistent.hasFlags()
+}
+
+// Inherit adds the persistent flags of a parent FlagSet, so that they can be
+// parsed along with the other flags. Flags that are already defined are not
+// inherited.
+func (f *FlagSet) Inherit(parent *FlagSet) {
+	if !parent.HasPersistent() {
+		return
+	}
+	parent.persistent.VisitAll(func(p *flag.Flag) {
+		if f.own().Lookup(p.Name) != nil {
+			return
+		}
+		f.flag.Var(p.Value, p.Name, p.Usage)
+		f.inherited[p.Name] = struct{}{}
+		if short := parent.persistent.Short(p.Name); short != "" && f.own().Lookup(short) == nil {
+			f.alias(short, p.Name)
+		}
+		if predictor, ok := parent.persistent.predictors[p.Name]; ok {
+			f.predictors[p.Name] = predictor
+		}
+	})
+	for _, c := range parent.persistent.constraints {
+		if f.inheritsAll(c.names) && !f.hasConstraint(c) {
+			f.constraints = append(f.constraints, c)
+		}
+	}
+}
+
+// Inherited returns if the named flag was inherited from a parent FlagSet.
+func (f *FlagSet) Inherited(name string) bool {
+	_, ok := f.inherited[name]
+	return ok
+}
+
+// SetConfig sets the values from a config file, keyed by the flag name. Config
+// values are used for flags that aren't passed as a flag, or set from the
+// environment.
+func (f *FlagSet) SetConfig(values map[string]string) {
+	f.config = values
+}
+
+// SetEnv sets the function used to look up environment variables, which is
+// os.LookupEnv by default.
+func (f *FlagSet) SetEnv(lookupEnv func(string) (string, bool)) {
+	f.lookupEnv = lookupEnv
+}
+
+// SetEnvPrefix sets the prefix of the environment variables that are bound to
+// the flags, so a prefix of "app" binds the "user" flag to "APP_USER". There
+// is no prefix by default.
+func (f *FlagSet) SetEnvPrefix(prefix string) {
+	f.envPrefix = prefix
+}
+
+// SetReadFile sets the function used to read the ENV_FILE, which is
+// ioutil.ReadFile by default.
+func (f *FlagSet) SetReadFile(readFile func(string) ([]byte, error)) {
+	f.readFile = readFile
+}
+
+// SetPredictor sets the predictor for the values of the named flag, which is
+// used for autocompleting the values. The predictors in the args package can
+// predict a set of values, files or the values from a function.
+func (f *FlagSet) SetPredictor(name string, predictor args.Predictor) {
+	f.predictors[name] = predictor
+}
+
+// Predictor returns the predictor for the values of the named flag.
+func (f *FlagSet) Predictor(name string) (args.Predictor, bool) {
+	if predictor, ok := f.predictors[name]; ok {
+		return predictor, true
+	}
+	if f.persistent != nil {
+		return f.persistent.Predictor(name)
+	}
+	return nil, false
+}
+
+// Origin returns where the value of the named flag came from, once the
+// FlagSet has been parsed.
+func (f *FlagSet) Origin(name string) Origin {
+	return f.origins[name]
+}
+
+// Reset sets every flag back to its default value, so that the FlagSet can be
+// parsed again.
+func (f *FlagSet) Reset() {
+	f.VisitAll(func(fl *flag.Flag) {
+		if r, ok := fl.Value.(resetter); ok {
+			r.reset()
+			return
+		}
+		fl.Value.Set(fl.DefValue)
+	})
+}
+
+// Short returns the short name of the named flag, or an empty string if the
+// flag doesn't have a short name.
+func (f *FlagSet) Short(name string) string {
+	f.own()
+	for short, long := range f.shorts {
+		if long == name {
+			return short
+		}
+	}
+	return ""
+}
+
+// Type returns the type of value the named flag expects, such as "string"
+// or "size". Returns an empty string for boolean flags, or if the flag doesn't
+// exist.
+func (f *FlagSet) Type(name string) string {
+	fl := f.Lookup(name)
+	if fl == nil {
+		return ""
+	}
+	if v, ok := fl.Value.(interface{ Type() string }); ok {
+		return v.Type()
+	}
+	typ, _ := flag.UnquoteUsage(fl)
+	return typ
+}
+
 // VisitAll visits the flags in lexicographical order, calling fn for each.
-// It visits all flags, even those not set.
+// It visits all flags, even those not set. Short names aren't visited, as
+// they're the same flag as the long name.
 func (f *FlagSet) VisitAll(fn func(*flag.Flag)) {
-	f.flag.VisitAll(fn)
+	f.own().VisitAll(func(fl *flag.Flag) {
+		if _, ok := f.shorts[fl.Name]; !ok {
+			fn(fl)
+		}
+	})
 }
 
 // Visit visits the flags in lexicographical order, calling fn for each.
-// It visits only those flags that have been set.
+// It visits only those flags that have been set. Flags set by their short
+// name are visited by their long name.
 func (f *FlagSet) Visit(fn func(*flag.Flag)) {
-	f.flag.Visit(fn)
+	visited := make(map[string]struct{})
+	f.own().Visit(func(fl *flag.Flag) {
+		if long, ok := f.shorts[fl.Name]; ok {
+			fl = f.flag.Lookup(long)
+		}
+		if _, ok := visited[fl.Name]; ok {
+			return
+		}
+		visited[fl.Name] = struct{}{}
+		fn(fl)
+	})
 }
 
-// Lookup returns the Flag structure of the named flag, returning nil if none exists.
+// Lookup returns the Flag structure of the named flag, returning nil if none
+// exists. Looking up a short name returns the flag for the long name.
 func (f *FlagSet) Lookup(name string) *flag.Flag {
-	return f.flag.Lookup(name)
+	fs := f.own()
+	if long, ok := f.shorts[name]; ok {
+		name = long
+	}
+	return fs.Lookup(name)
 }
 
 // Set sets the value of the named flag.
 func (f *FlagSet) Set(name, value string) error {
-	return f.flag.Set(name, value)
+	fs := f.own()
+	if long, ok := f.shorts[name]; ok {
+		name = long
+	}
+	return fs.Set(name, value)
 }
 
 // PrintDefaults prints to standard error the default values of all
 // defined command-line flags in the set. See the documentation for
 // the global function PrintDefaults for more information.
 func (f *FlagSet) PrintDefaults() {
-	f.flag.PrintDefaults()
+	f.own().PrintDefaults()
 }
 
 // NFlag returns the number of flags that have been set.
@@ -210,38 +420,36 @@ func (f *FlagSet) Var(value flag.Value, name string, usage string) {
 // Parse parses flag definitions from the argument list, which should not
 // include the command name. Must be called after all flags in the FlagSet
 // are defined and before flags are accessed by the program.
+// Flags can be placed before and after the arguments, see Split.
 // The return value will be ErrHelp if -help or -h were set but not defined.
 func (f *FlagSet) Parse(arguments []string) error {
 	if f.Usage != nil {
 		f.flag.Usage = f.Usage
 	}
 
-	if err := f.flag.Parse(arguments); err != nil {
+	// Slices and maps add to their value, so they're reset to their default
+	// before parsing, otherwise values are kept from the last parse.
+	f.VisitAll(func(fl *flag.Flag) {
+		if r, ok := fl.Value.(resetter); ok {
+			r.reset()
+		}
+	})
+
+	flagArgs, args := f.Split(arguments)
+	if err := f.own().Parse(flagArgs); err != nil {
 		return err
 	}
 
 	f.src = arguments[:]
+	f.args = args
+	f.flags = nil
+	f.origins = make(map[string]Origin)
 
 	flags := make(map[string]struct{})
-	for _, v := range f.flag.Args() {
-		if v == "-" || !strings.HasPrefix(v, "-") {
-			f.args = append(f.args, v)
-		} else {
-			var i int
-			name := strings.TrimLeftFunc(v, func(r rune) bool {
-				i++
-				if i <= 2 {
-					return r == '-'
-				}
-				return false
-			})
-			flags[name] = struct{}{}
-		}
-	}
 
 	fileArgs := make(map[string]string)
-	if fileName, ok := syscall.Getenv("ENV_FILE"); ok && fileName != "" {
-		if file, err := ioutil.ReadFile(fileName); err == nil {
+	if fileName, ok := f.lookupEnv("ENV_FILE"); ok && fileName != "" {
+		if file, err := f.readFile(fileName); err == nil {
 			scanner := bufio.NewScanner(bytes.NewReader(file))
 			for scanner.Scan() {
 				parts := strings.Split(scanner.Text(), "=")
@@ -252,16 +460,30 @@ func (f *FlagSet) Parse(arguments []string) error {
 		}
 	}
 
+	// Flags take precedence over the environment, which takes precedence over
+	// the config file.
 	f.Visit(func(flag *flag.Flag) {
-		name := envName(flag.Name)
-		if value, ok := fileArgs[name]; ok {
-			f.flag.Set(flag.Name, value)
+		f.origins[flag.Name] = OriginFlag
+		flags[flag.Name] = struct{}{}
+	})
+
+	var err error
+	f.VisitAll(func(flag *flag.Flag) {
+		if _, ok := flags[flag.Name]; ok || err != nil {
+			return
 		}
-		if value, ok := syscall.Getenv(name); ok {
-			f.flag.Set(flag.Name, value)
+		name := envName(f.envPrefix, flag.Name)
+		if value, ok := f.lookupEnv(name); ok {
+			err = f.setFrom(flag, value, OriginEnv)
+		} else if value, ok := fileArgs[name]; ok {
+			err = f.setFrom(flag, value, OriginEnv)
+		} else if value, ok := f.config[flag.Name]; ok {
+			err = f.setFrom(flag, value, OriginConfig)
 		}
-		flags[flag.Name] = struct{}{}
 	})
+	if err != nil {
+		return err
+	}
 
 	for k := range flags {
 		f.flags = append(f.flags, k)
@@ -270,6 +492,57 @@ func (f *FlagSet) Parse(arguments []string) error {
 	return nil
 }
 
-func envName(name string) string {
+// own returns the underlying flag set, after ensuring that any persistent
+// flags are also defined on it.
+func (f *FlagSet) own() *flag.FlagSet {
+	if f.persistent != nil {
+		f.persistent.flag.VisitAll(func(p *flag.Flag) {
+			if f.flag.Lookup(p.Name) == nil {
+				f.flag.Var(p.Value, p.Name, p.Usage)
+				if long, ok := f.persistent.shorts[p.Name]; ok {
+					f.shorts[p.Name] = long
+				}
+			}
+		})
+	}
+	return f.flag
+}
+
+// alias defines the short name for the flag with the long name.
+func (f *FlagSet) alias(short, name string) {
+	long := f.flag.Lookup(name)
+	f.flag.Var(long.Value, short, long.Usage)
+	f.shorts[short] = name
+}
+
+func (f *FlagSet) setFrom(flag *flag.Flag, value string, origin Origin) error {
+	if err := flag.Value.Set(value); err != nil {
+		return errors.Errorf("invalid value %q for flag -%s from %s: %v", value, flag.Name, origin, err)
+	}
+	f.origins[flag.Name] = origin
+	return nil
+}
+
+func (f *FlagSet) inheritsAll(names []string) bool {
+	for _, name := range names {
+		if !f.Inherited(name) {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *FlagSet) hasFlags() bool {
+	var found bool
+	f.flag.VisitAll(func(*flag.Flag) {
+		found = true
+	})
+	return found
+}
+
+func envName(prefix, name string) string {
+	if prefix != "" {
+		name = strings.Replace(prefix, "-", "_", -1) + "_" + name
+	}
 	return strings.Replace(strings.ToUpper(name), ".", "_", -1)
 }
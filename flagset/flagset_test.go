@@ -5,28 +5,41 @@ import (
 	"fmt"
 	"io/ioutil"
 	"math/rand"
+	"net"
+	"net/url"
 	"os"
 	"reflect"
 	"testing"
 	"testing/quick"
+	"time"
+
+	"github.com/spoke-d/clui/autocomplete/args"
 )
 
 func TestReadingFromEnv(t *testing.T) {
 	fn := func(envValue, defaultValue, cmdValue ASCII) bool {
 		os.Setenv("TEST", envValue.String())
 
-		flagset := New("test", flag.ExitOnError)
+		defer os.Unsetenv("TEST")
 
+		flagset := New("test", flag.ExitOnError)
 		test := flagset.String("test", defaultValue.String(), "test value")
+		if err := flagset.Parse([]string{}); err != nil {
+			t.Fatal(err)
+		}
+		if *test != envValue.String() || flagset.Origin("test") != OriginEnv {
+			return false
+		}
+
+		// Flags take precedence over the environment.
+		flagset = New("test", flag.ExitOnError)
+		test = flagset.String("test", defaultValue.String(), "test value")
 
 		args := []string{fmt.Sprintf("-test=%s", cmdValue.String())}
 		if err := flagset.Parse(args); err != nil {
 			t.Fatal(err)
 		}
-
-		os.Unsetenv("TEST")
-
-		return *test == envValue.String()
+		return *test == cmdValue.String() && flagset.Origin("test") == OriginFlag
 	}
 	if err := quick.Check(fn, nil); err != nil {
 		t.Error(err)
@@ -50,36 +63,854 @@ func TestReadingFromEnvFile(t *testing.T) {
 		}
 
 		os.Setenv("ENV_FILE", tmpfile.Name())
+		defer os.Unsetenv("ENV_FILE")
 
 		flagset := New("test", flag.ExitOnError)
 		test := flagset.String("test", defaultValue.String(), "test value")
+		if err := flagset.Parse([]string{}); err != nil {
+			t.Fatal(err)
+		}
+		if *test != envValue.String() {
+			return false
+		}
+
+		// Flags take precedence over the environment file.
+		flagset = New("test", flag.ExitOnError)
+		test = flagset.String("test", defaultValue.String(), "test value")
 
 		args := []string{fmt.Sprintf("-test=%s", cmdValue.String())}
 		if err := flagset.Parse(args); err != nil {
 			t.Fatal(err)
 		}
-
-		os.Unsetenv("ENV_FILE")
-
-		return *test == envValue.String()
+		return *test == cmdValue.String()
 	}
 	if err := quick.Check(fn, nil); err != nil {
 		t.Error(err)
 	}
 }
 
-func TestEnvName(t *testing.T) {
+func TestPersistent(t *testing.T) {
+	t.Run("parse own persistent", func(t *testing.T) {
+		parent := New("parent", flag.ContinueOnError)
+		server := parent.Persistent().String("server", "", "server address")
+
+		if err := parent.Parse([]string{"--server=localhost"}); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := "localhost", *server; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := false, parent.Inherited("server"); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("parse inherited", func(t *testing.T) {
+		parent := New("parent", flag.ContinueOnError)
+		server := parent.Persistent().String("server", "", "server address")
+
+		child := New("child", flag.ContinueOnError)
+		child.Inherit(parent)
+
+		if err := child.Parse([]string{"--server=localhost"}); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := "localhost", *server; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := true, child.Inherited("server"); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("inherit does not override", func(t *testing.T) {
+		parent := New("parent", flag.ContinueOnError)
+		parent.Persistent().String("server", "", "server address")
+
+		child := New("child", flag.ContinueOnError)
+		server := child.String("server", "", "child server address")
+		child.Inherit(parent)
+
+		if err := child.Parse([]string{"--server=localhost"}); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := "localhost", *server; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := false, child.Inherited("server"); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("has persistent", func(t *testing.T) {
+		parent := New("parent", flag.ContinueOnError)
+		if expected, actual := false, parent.HasPersistent(); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		parent.Persistent().Bool("force", false, "force")
+		if expected, actual := true, parent.HasPersistent(); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+}
+
+func TestConfig(t *testing.T) {
+	t.Run("config", func(t *testing.T) {
+		flagset := New("test", flag.ContinueOnError)
+		test := flagset.String("test", "default", "test value")
+		other := flagset.String("other", "default", "other value")
+		flagset.SetConfig(map[string]string{"test": "config"})
+
+		if err := flagset.Parse([]string{}); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := "config", *test; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := OriginConfig, flagset.Origin("test"); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := "default", *other; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := OriginDefault, flagset.Origin("other"); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("env over config", func(t *testing.T) {
+		os.Setenv("TEST", "env")
+		defer os.Unsetenv("TEST")
+
+		flagset := New("test", flag.ContinueOnError)
+		test := flagset.String("test", "default", "test value")
+		flagset.SetConfig(map[string]string{"test": "config"})
+
+		if err := flagset.Parse([]string{}); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := "env", *test; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := OriginEnv, flagset.Origin("test"); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("env prefix", func(t *testing.T) {
+		env := map[string]string{"USER": "root", "MY_CLI_PASSWORD": "secret"}
+
+		flagset := New("test", flag.ContinueOnError)
+		user := flagset.String("user", "default", "user value")
+		password := flagset.String("password", "default", "password value")
+		flagset.SetEnvPrefix("my-cli")
+		flagset.SetEnv(func(name string) (string, bool) {
+			value, ok := env[name]
+			return value, ok
+		})
+
+		if err := flagset.Parse([]string{}); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := "default", *user; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := "secret", *password; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("env file from read file", func(t *testing.T) {
+		flagset := New("test", flag.ContinueOnError)
+		test := flagset.String("test", "default", "test value")
+		flagset.SetEnv(func(name string) (string, bool) {
+			return "/.env", name == "ENV_FILE"
+		})
+		flagset.SetReadFile(func(name string) ([]byte, error) {
+			if name != "/.env" {
+				return nil, os.ErrNotExist
+			}
+			return []byte("TEST=file\n"), nil
+		})
+
+		if err := flagset.Parse([]string{}); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := "file", *test; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := OriginEnv, flagset.Origin("test"); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("flag over config", func(t *testing.T) {
+		flagset := New("test", flag.ContinueOnError)
+		test := flagset.String("test", "default", "test value")
+		flagset.SetConfig(map[string]string{"test": "config"})
+
+		if err := flagset.Parse([]string{"-test=flag"}); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := "flag", *test; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := OriginFlag, flagset.Origin("test"); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		flagset := New("test", flag.ContinueOnError)
+		flagset.Int("test", 0, "test value")
+		flagset.SetConfig(map[string]string{"test": "bad"})
+
+		err := flagset.Parse([]string{})
+		if expected, actual := `invalid value "bad" for flag -test from config: parse error`, fmt.Sprint(err); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+}
+
+func TestPredictor(t *testing.T) {
+	t.Run("predictor", func(t *testing.T) {
+		flagset := New("test", flag.ContinueOnError)
+		flagset.String("format", "text", "format value")
+		flagset.SetPredictor("format", args.PredictSet("text", "json"))
+
+		predictor, ok := flagset.Predictor("format")
+		if expected, actual := true, ok; expected != actual {
+			t.Fatalf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := []string{"text", "json"}, predictor.Predict(args.New("cli --format ")); !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if _, ok := flagset.Predictor("other"); ok {
+			t.Errorf("expected no predictor for other")
+		}
+	})
+
+	t.Run("inherited", func(t *testing.T) {
+		parent := New("parent", flag.ContinueOnError)
+		parent.Persistent().String("format", "text", "format value")
+		parent.Persistent().SetPredictor("format", args.PredictSet("text", "json"))
+
+		if _, ok := parent.Predictor("format"); !ok {
+			t.Errorf("expected predictor for persistent flag")
+		}
+
+		child := New("child", flag.ContinueOnError)
+		child.Inherit(parent)
+
+		predictor, ok := child.Predictor("format")
+		if expected, actual := true, ok; expected != actual {
+			t.Fatalf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := []string{"text", "json"}, predictor.Predict(args.New("cli --format ")); !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+}
+
+func TestShort(t *testing.T) {
+	t.Parallel()
+
+	t.Run("parse", func(t *testing.T) {
+		flagset := New("test", flag.ContinueOnError)
+		var output string
+		flagset.StringVarP(&output, "output", "o", "text", "output format")
+
+		if err := flagset.Parse([]string{"-o", "json"}); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := "json", output; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := OriginFlag, flagset.Origin("output"); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := "o", flagset.Short("output"); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := "output", flagset.Lookup("o").Name; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("env", func(t *testing.T) {
+		os.Setenv("OUTPUT", "yaml")
+		defer os.Unsetenv("OUTPUT")
+
+		flagset := New("test", flag.ContinueOnError)
+		var output string
+		flagset.StringVarP(&output, "output", "o", "text", "output format")
+
+		if err := flagset.Parse([]string{}); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := "yaml", output; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := OriginEnv, flagset.Origin("output"); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("visit all", func(t *testing.T) {
+		flagset := New("test", flag.ContinueOnError)
+		var verbose bool
+		flagset.BoolVarP(&verbose, "verbose", "V", false, "verbose output")
+
+		var names []string
+		flagset.VisitAll(func(f *flag.Flag) {
+			names = append(names, f.Name)
+		})
+		if expected, actual := []string{"verbose"}, names; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("persistent", func(t *testing.T) {
+		parent := New("parent", flag.ContinueOnError)
+		var output string
+		parent.Persistent().StringVarP(&output, "output", "o", "text", "output format")
+
+		child := New("child", flag.ContinueOnError)
+		child.Inherit(parent)
+
+		if err := child.Parse([]string{"-o", "json"}); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := "json", output; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := "o", child.Short("output"); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+}
+
+func TestConstraints(t *testing.T) {
+	t.Parallel()
+
+	newFlagSet := func() *FlagSet {
+		flagset := New("test", flag.ContinueOnError)
+		flagset.SetEnv(func(string) (string, bool) { return "", false })
+		flagset.String("name", "", "name")
+		flagset.Bool("json", false, "json output")
+		flagset.String("template", "", "template output")
+		flagset.String("user", "", "user")
+		flagset.String("password", "", "password")
+		flagset.MarkRequired("name")
+		flagset.MarkExclusive("json", "template")
+		flagset.MarkRequiredTogether("user", "password")
+		return flagset
+	}
+
+	for _, testcase := range []struct {
+		name       string
+		args       []string
+		violations []string
+	}{
+		{
+			name: "valid",
+			args: []string{"--name=fred", "--json", "--user=fred", "--password=secret"},
+		},
+		{
+			name:       "required",
+			args:       []string{},
+			violations: []string{"required flag --name not set"},
+		},
+		{
+			name: "all violations",
+			args: []string{"--json", "--template=x", "--password=secret"},
+			violations: []string{
+				"required flag --name not set",
+				"flags --json, --template can not be used together",
+				"flags --user, --password must be used together, missing --user",
+			},
+		},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			flagset := newFlagSet()
+			if err := flagset.Parse(testcase.args); err != nil {
+				t.Fatal(err)
+			}
+
+			var violations []string
+			if err := flagset.Validate(); err != nil {
+				violations = err.(*ConstraintError).Violations
+			}
+			if expected, actual := testcase.violations, violations; !reflect.DeepEqual(expected, actual) {
+				t.Errorf("expected: %v, actual: %v", expected, actual)
+			}
+		})
+	}
+
+	t.Run("required from env", func(t *testing.T) {
+		flagset := New("test", flag.ContinueOnError)
+		flagset.SetEnv(func(name string) (string, bool) { return "fred", name == "NAME" })
+		flagset.String("name", "", "name")
+		flagset.MarkRequired("name")
+
+		if err := flagset.Parse([]string{}); err != nil {
+			t.Fatal(err)
+		}
+		if err := flagset.Validate(); err != nil {
+			t.Errorf("expected nil error, actual: %v", err)
+		}
+	})
+
+	t.Run("inherited", func(t *testing.T) {
+		parent := New("parent", flag.ContinueOnError)
+		parent.Persistent().String("server", "", "server address")
+		parent.Persistent().MarkRequired("server")
+
+		child := New("child", flag.ContinueOnError)
+		child.SetEnv(func(string) (string, bool) { return "", false })
+		child.Inherit(parent)
+		child.Inherit(parent)
+
+		if expected, actual := true, child.Required("server"); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if err := child.Parse([]string{}); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := "required flag --server not set", fmt.Sprint(child.Validate()); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+}
+
+func TestValues(t *testing.T) {
+	t.Parallel()
+
+	t.Run("string slice", func(t *testing.T) {
+		for _, testcase := range []struct {
+			name string
+			args []string
+			want []string
+		}{
+			{"default", []string{}, []string{"a"}},
+			{"repeated", []string{"--tag=b", "--tag=c"}, []string{"b", "c"}},
+			{"comma separated", []string{"--tag=b,c", "--tag=d"}, []string{"b", "c", "d"}},
+		} {
+			t.Run(testcase.name, func(t *testing.T) {
+				flagset := New("test", flag.ContinueOnError)
+				flagset.SetEnv(func(string) (string, bool) { return "", false })
+				tags := flagset.StringSlice("tag", []string{"a"}, "tags")
+
+				if err := flagset.Parse(testcase.args); err != nil {
+					t.Fatal(err)
+				}
+				if expected, actual := testcase.want, *tags; !reflect.DeepEqual(expected, actual) {
+					t.Errorf("expected: %v, actual: %v", expected, actual)
+				}
+			})
+		}
+	})
+
+	t.Run("string slice parsed twice", func(t *testing.T) {
+		flagset := New("test", flag.ContinueOnError)
+		flagset.SetEnv(func(string) (string, bool) { return "", false })
+		tags := flagset.StringSlice("tag", []string{"a"}, "tags")
+		labels := flagset.StringMap("label", map[string]string{"a": "b"}, "labels")
+
+		for _, testcase := range []struct {
+			args   []string
+			tags   []string
+			labels map[string]string
+		}{
+			{[]string{"--tag=b", "--label=c=d"}, []string{"b"}, map[string]string{"c": "d"}},
+			{[]string{"--tag=a", "--tag=c", "--label=e=f"}, []string{"a", "c"}, map[string]string{"e": "f"}},
+			{[]string{}, []string{"a"}, map[string]string{"a": "b"}},
+		} {
+			if err := flagset.Parse(testcase.args); err != nil {
+				t.Fatal(err)
+			}
+			if expected, actual := testcase.tags, *tags; !reflect.DeepEqual(expected, actual) {
+				t.Errorf("expected: %v, actual: %v", expected, actual)
+			}
+			if expected, actual := testcase.labels, *labels; !reflect.DeepEqual(expected, actual) {
+				t.Errorf("expected: %v, actual: %v", expected, actual)
+			}
+		}
+	})
+
+	t.Run("string slice from env", func(t *testing.T) {
+		flagset := New("test", flag.ContinueOnError)
+		flagset.SetEnv(func(name string) (string, bool) { return "b,c", name == "TAG" })
+		tags := flagset.StringSlice("tag", []string{"a"}, "tags")
+
+		if err := flagset.Parse([]string{}); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := []string{"b", "c"}, *tags; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("string map", func(t *testing.T) {
+		flagset := New("test", flag.ContinueOnError)
+		flagset.SetEnv(func(string) (string, bool) { return "", false })
+		labels := flagset.StringMap("label", map[string]string{"a": "b"}, "labels")
+
+		if err := flagset.Parse([]string{"--label=env=prod,team=core", "--label=url=a=b"}); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := map[string]string{"env": "prod", "team": "core", "url": "a=b"}, *labels; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := "env=prod,team=core,url=a=b", flagset.Lookup("label").Value.String(); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("string map invalid", func(t *testing.T) {
+		flagset := New("test", flag.ContinueOnError)
+		flagset.StringMap("label", nil, "labels")
+
+		if err := flagset.Parse([]string{"--label=env"}); err == nil {
+			t.Errorf("expected error")
+		}
+	})
+
+	t.Run("enum", func(t *testing.T) {
+		flagset := New("test", flag.ContinueOnError)
+		flagset.SetEnv(func(string) (string, bool) { return "", false })
+		output := flagset.Enum("output", "text", []string{"text", "json"}, "output format")
+
+		if err := flagset.Parse([]string{"--output=json"}); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := "json", *output; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+
+		choices, ok := flagset.Choices("output")
+		if expected, actual := true, ok; expected != actual {
+			t.Fatalf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := []string{"text", "json"}, choices; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+
+		predictor, ok := flagset.Predictor("output")
+		if expected, actual := true, ok; expected != actual {
+			t.Fatalf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := []string{"text", "json"}, predictor.Predict(args.New("cli --output ")); !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("enum invalid", func(t *testing.T) {
+		flagset := New("test", flag.ContinueOnError)
+		flagset.Enum("output", "text", []string{"text", "json"}, "output format")
+
+		err := flagset.Parse([]string{"--output=xml"})
+		if expected, actual := `invalid value "xml" for flag -output: must be one of text, json`, fmt.Sprint(err); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+}
+
+func TestParseSize(t *testing.T) {
 	for _, testcase := range []struct {
 		value string
+		want  uint64
+	}{
+		{"512", 512},
+		{"512B", 512},
+		{"10KB", 10000},
+		{"10MiB", 10 << 20},
+		{"10mib", 10 << 20},
+		{"1.5G", 1500000000},
+		{"2Ti", 2 << 40},
+		{"1.1KB", 1100},
+		{"0.5KiB", 512},
+		{"18446744073709551615", 18446744073709551615},
+	} {
+		t.Run(testcase.value, func(t *testing.T) {
+			size, err := ParseSize(testcase.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expected, actual := testcase.want, size; expected != actual {
+				t.Errorf("expected: %v, actual: %v", expected, actual)
+			}
+		})
+	}
+
+	for _, value := range []string{"", "MiB", "-1", "10XB", "1.2.3K", "1.5B", "0.1", "20000000PB", "18446744073709551616"} {
+		t.Run(value, func(t *testing.T) {
+			if _, err := ParseSize(value); err == nil {
+				t.Errorf("expected error")
+			}
+		})
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	for _, testcase := range []struct {
+		value uint64
 		want  string
 	}{
-		{"name", "NAME"},
-		{"name.subname", "NAME_SUBNAME"},
-		{"name..SubName", "NAME__SUBNAME"},
-		{".NAmE.", "_NAME_"},
+		{0, "0B"},
+		{512, "512B"},
+		{10 << 20, "10MiB"},
+		{1500000000, "1500MB"},
+	} {
+		t.Run(testcase.want, func(t *testing.T) {
+			if expected, actual := testcase.want, FormatSize(testcase.value); expected != actual {
+				t.Errorf("expected: %v, actual: %v", expected, actual)
+			}
+		})
+	}
+}
+
+func TestUnits(t *testing.T) {
+	newFlagSet := func() *FlagSet {
+		flagset := New("test", flag.ContinueOnError)
+		flagset.SetEnv(func(string) (string, bool) { return "", false })
+		return flagset
+	}
+
+	t.Run("size", func(t *testing.T) {
+		flagset := newFlagSet()
+		limit := flagset.Size("limit", 1<<20, "limit")
+
+		if expected, actual := "1MiB", flagset.Lookup("limit").DefValue; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if err := flagset.Parse([]string{"--limit=10MiB"}); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := uint64(10<<20), *limit; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := "size", flagset.Type("limit"); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("time", func(t *testing.T) {
+		now := time.Date(2020, 1, 2, 15, 4, 5, 0, time.UTC)
+		timeNow = func() time.Time { return now }
+		defer func() { timeNow = time.Now }()
+
+		for _, testcase := range []struct {
+			value string
+			want  time.Time
+		}{
+			{"2006-01-02T15:04:05Z", time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)},
+			{"-2h", now.Add(-2 * time.Hour)},
+			{"+30m", now.Add(30 * time.Minute)},
+		} {
+			flagset := newFlagSet()
+			since := flagset.Time("since", time.Time{}, "since")
+
+			if err := flagset.Parse([]string{"--since=" + testcase.value}); err != nil {
+				t.Fatal(err)
+			}
+			if expected, actual := testcase.want, *since; !expected.Equal(actual) {
+				t.Errorf("expected: %v, actual: %v", expected, actual)
+			}
+		}
+
+		flagset := newFlagSet()
+		flagset.Time("since", time.Time{}, "since")
+		if err := flagset.Parse([]string{"--since=yesterday"}); err == nil {
+			t.Errorf("expected error")
+		}
+	})
+
+	t.Run("url", func(t *testing.T) {
+		flagset := newFlagSet()
+		endpoint := flagset.URL("endpoint", &url.URL{Scheme: "https", Host: "localhost"}, "endpoint")
+
+		if expected, actual := "https://localhost", flagset.Lookup("endpoint").DefValue; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if err := flagset.Parse([]string{"--endpoint=http://example.com:8080/api"}); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := "example.com:8080", endpoint.Host; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+
+		flagset = newFlagSet()
+		flagset.URL("endpoint", nil, "endpoint")
+		if err := flagset.Parse([]string{"--endpoint=example.com"}); err == nil {
+			t.Errorf("expected error")
+		}
+	})
+
+	t.Run("short names", func(t *testing.T) {
+		var (
+			limit    uint64
+			since    time.Time
+			endpoint url.URL
+			addr     string
+			ip       net.IP
+			cidr     net.IPNet
+		)
+		flagset := newFlagSet()
+		flagset.SizeVarP(&limit, "limit", "l", 0, "limit")
+		flagset.TimeVarP(&since, "since", "s", time.Time{}, "since")
+		flagset.URLVarP(&endpoint, "endpoint", "e", nil, "endpoint")
+		flagset.HostPortVarP(&addr, "addr", "a", "", "addr")
+		flagset.IPVarP(&ip, "ip", "i", nil, "ip")
+		flagset.CIDRVarP(&cidr, "cidr", "c", nil, "cidr")
+
+		err := flagset.Parse([]string{
+			"-l", "1KiB",
+			"-s", "2006-01-02T15:04:05Z",
+			"-e", "https://localhost",
+			"-a", "localhost:8080",
+			"-i", "10.0.0.1",
+			"-c", "10.0.0.0/8",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for name, want := range map[string]interface{}{
+			"limit":    uint64(1024),
+			"since":    time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC),
+			"endpoint": url.URL{Scheme: "https", Host: "localhost"},
+			"addr":     "localhost:8080",
+			"ip":       net.ParseIP("10.0.0.1"),
+			"cidr":     net.IPNet{IP: net.IP{10, 0, 0, 0}, Mask: net.CIDRMask(8, 32)},
+		} {
+			actual := flagset.Lookup(name).Value.(flag.Getter).Get()
+			if expected := want; !reflect.DeepEqual(expected, actual) {
+				t.Errorf("%s: expected: %#v, actual: %#v", name, expected, actual)
+			}
+		}
+	})
+
+	t.Run("host port", func(t *testing.T) {
+		flagset := newFlagSet()
+		addr := flagset.HostPort("addr", "", "addr")
+
+		if err := flagset.Parse([]string{"--addr=localhost:8080"}); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := "localhost:8080", *addr; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+
+		for _, value := range []string{"localhost", "localhost:http", "localhost:70000"} {
+			flagset := newFlagSet()
+			flagset.HostPort("addr", "", "addr")
+			if err := flagset.Parse([]string{"--addr=" + value}); err == nil {
+				t.Errorf("expected error for %q", value)
+			}
+		}
+	})
+
+	t.Run("ip", func(t *testing.T) {
+		flagset := newFlagSet()
+		ip := flagset.IP("ip", nil, "ip")
+		cidr := flagset.CIDR("cidr", nil, "cidr")
+
+		if err := flagset.Parse([]string{"--ip=10.0.0.1", "--cidr=10.0.0.1/8"}); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := "10.0.0.1", ip.String(); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := "10.0.0.0/8", cidr.String(); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+
+		flagset = newFlagSet()
+		flagset.IP("ip", nil, "ip")
+		flagset.CIDR("cidr", nil, "cidr")
+		if err := flagset.Parse([]string{"--ip=10.0.0.256"}); err == nil {
+			t.Errorf("expected error")
+		}
+		if err := flagset.Parse([]string{"--cidr=10.0.0.1"}); err == nil {
+			t.Errorf("expected error")
+		}
+	})
+}
+
+func TestSplit(t *testing.T) {
+	flagset := New("test", flag.ContinueOnError)
+	flagset.StringVarP(new(string), "dest", "d", "", "destination")
+	flagset.BoolVarP(new(bool), "all", "a", false, "all")
+	flagset.BoolVarP(new(bool), "force", "f", false, "force")
+	flagset.Bool("no-cache", false, "no cache")
+
+	for _, testcase := range []struct {
+		name  string
+		args  []string
+		flags []string
+		rest  []string
+	}{
+		{"value after flag", []string{"--dest", "x", "src"}, []string{"--dest=x"}, []string{"src"}},
+		{"flags after args", []string{"src", "--dest=x", "-f"}, []string{"--dest=x", "--force"}, []string{"src"}},
+		{"short value", []string{"-d", "x", "src"}, []string{"--dest=x"}, []string{"src"}},
+		{"combined shorts", []string{"-af", "src"}, []string{"--all", "--force"}, []string{"src"}},
+		{"combined shorts with value", []string{"-afd", "x"}, []string{"--all", "--force", "--dest=x"}, nil},
+		{"negated", []string{"--no-force"}, []string{"--force=false"}, nil},
+		{"negated defined", []string{"--no-cache"}, []string{"--no-cache"}, nil},
+		{"terminator", []string{"-f", "--", "--dest", "x"}, []string{"--force"}, []string{"--dest", "x"}},
+		{"unknown", []string{"--other", "x"}, []string{"--other"}, []string{"x"}},
+		{"missing value", []string{"src", "--dest"}, []string{"--dest"}, []string{"src"}},
+		{"stdin", []string{"-"}, nil, []string{"-"}},
+	} {
+		t.Run(testcase.name, func(t *testing.T) {
+			flags, rest := flagset.Split(testcase.args)
+			if expected, actual := testcase.flags, flags; !reflect.DeepEqual(expected, actual) {
+				t.Errorf("expected: %v, actual: %v", expected, actual)
+			}
+			if expected, actual := testcase.rest, rest; !reflect.DeepEqual(expected, actual) {
+				t.Errorf("expected: %v, actual: %v", expected, actual)
+			}
+		})
+	}
+}
+
+func TestParseInterspersed(t *testing.T) {
+	flagset := New("test", flag.ContinueOnError)
+	flagset.SetEnv(func(string) (string, bool) { return "", false })
+	dest := flagset.String("dest", "", "destination")
+	var force bool
+	flagset.BoolVarP(&force, "force", "f", true, "force")
+
+	if err := flagset.Parse([]string{"src", "--dest", "x", "--no-force", "other"}); err != nil {
+		t.Fatal(err)
+	}
+	if expected, actual := "x", *dest; expected != actual {
+		t.Errorf("expected: %v, actual: %v", expected, actual)
+	}
+	if expected, actual := false, force; expected != actual {
+		t.Errorf("expected: %v, actual: %v", expected, actual)
+	}
+	if expected, actual := []string{"src", "other"}, flagset.Args(); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected: %v, actual: %v", expected, actual)
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	for _, testcase := range []struct {
+		prefix string
+		value  string
+		want   string
+	}{
+		{"", "name", "NAME"},
+		{"", "name.subname", "NAME_SUBNAME"},
+		{"", "name..SubName", "NAME__SUBNAME"},
+		{"", ".NAmE.", "_NAME_"},
+		{"cli", "name", "CLI_NAME"},
+		{"my-cli", "name.subname", "MY_CLI_NAME_SUBNAME"},
 	} {
 		t.Run(testcase.value, func(t *testing.T) {
-			if expected, actual := testcase.want, envName(testcase.value); expected != actual {
+			if expected, actual := testcase.want, envName(testcase.prefix, testcase.value); expected != actual {
 				t.Errorf("expected: %s, actual: %s", expected, actual)
 			}
 		})
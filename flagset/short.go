@@ -0,0 +1,69 @@
+package flagset
+
+import (
+	"flag"
+	"time"
+)
+
+// BoolVarP is like BoolVar, but also accepts a short name, such as "v", that
+// can be used instead of the name.
+func (f *FlagSet) BoolVarP(p *bool, name, short string, value bool, usage string) {
+	f.BoolVar(p, name, value, usage)
+	f.alias(short, name)
+}
+
+// IntVarP is like IntVar, but also accepts a short name that can be used
+// instead of the name.
+func (f *FlagSet) IntVarP(p *int, name, short string, value int, usage string) {
+	f.IntVar(p, name, value, usage)
+	f.alias(short, name)
+}
+
+// Int64VarP is like Int64Var, but also accepts a short name that can be used
+// instead of the name.
+func (f *FlagSet) Int64VarP(p *int64, name, short string, value int64, usage string) {
+	f.Int64Var(p, name, value, usage)
+	f.alias(short, name)
+}
+
+// UintVarP is like UintVar, but also accepts a short name that can be used
+// instead of the name.
+func (f *FlagSet) UintVarP(p *uint, name, short string, value uint, usage string) {
+	f.UintVar(p, name, value, usage)
+	f.alias(short, name)
+}
+
+// Uint64VarP is like Uint64Var, but also accepts a short name that can be
+// used instead of the name.
+func (f *FlagSet) Uint64VarP(p *uint64, name, short string, value uint64, usage string) {
+	f.Uint64Var(p, name, value, usage)
+	f.alias(short, name)
+}
+
+// StringVarP is like StringVar, but also accepts a short name, such as "o",
+// that can be used instead of the name.
+func (f *FlagSet) StringVarP(p *string, name, short string, value string, usage string) {
+	f.StringVar(p, name, value, usage)
+	f.alias(short, name)
+}
+
+// Float64VarP is like Float64Var, but also accepts a short name that can be
+// used instead of the name.
+func (f *FlagSet) Float64VarP(p *float64, name, short string, value float64, usage string) {
+	f.Float64Var(p, name, value, usage)
+	f.alias(short, name)
+}
+
+// DurationVarP is like DurationVar, but also accepts a short name that can be
+// used instead of the name.
+func (f *FlagSet) DurationVarP(p *time.Duration, name, short string, value time.Duration, usage string) {
+	f.DurationVar(p, name, value, usage)
+	f.alias(short, name)
+}
+
+// VarP is like Var, but also accepts a short name that can be used instead of
+// the name.
+func (f *FlagSet) VarP(value flag.Value, name, short string, usage string) {
+	f.Var(value, name, usage)
+	f.alias(short, name)
+}
@@ -0,0 +1,101 @@
+package flagset
+
+import (
+	"flag"
+	"strings"
+)
+
+// Split separates the flags from the positional arguments, so that flags can
+// be placed before and after the positional arguments. Flags are returned in
+// the "--name=value" form, where:
+//
+//   - a flag that takes a value consumes the next argument, "--name value"
+//   - combined short boolean flags are expanded, "-abc"
+//   - boolean flags are negated with the "no-" prefix, "--no-name"
+//
+// Everything after "--" is a positional argument. Flags that aren't defined
+// are returned as they are, so that parsing them reports the error.
+func (f *FlagSet) Split(arguments []string) ([]string, []string) {
+	var flags, args []string
+	for i := 0; i < len(arguments); i++ {
+		arg := arguments[i]
+		if arg == "--" {
+			args = append(args, arguments[i+1:]...)
+			break
+		}
+		if arg == "-" || !strings.HasPrefix(arg, "-") {
+			args = append(args, arg)
+			continue
+		}
+
+		name := strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-")
+		if strings.Contains(name, "=") {
+			flags = append(flags, arg)
+			continue
+		}
+
+		names := []string{name}
+		if f.Lookup(name) == nil && !strings.HasPrefix(arg, "--") {
+			names = f.splitShorts(name)
+		}
+		for _, name := range names {
+			fl := f.Lookup(name)
+			switch {
+			case fl == nil:
+				if negated := f.negated(name); negated != nil {
+					flags = append(flags, "--"+negated.Name+"=false")
+				} else {
+					flags = append(flags, arg)
+				}
+			case isBoolFlag(fl):
+				flags = append(flags, "--"+fl.Name)
+			case i+1 < len(arguments):
+				i++
+				flags = append(flags, "--"+fl.Name+"="+arguments[i])
+			default:
+				// Let the parsing report the missing value.
+				flags = append(flags, "--"+fl.Name)
+			}
+		}
+	}
+	return flags, args
+}
+
+// splitShorts splits combined short flags, such as "abc", into the individual
+// short flags. Every short flag, except for the last, must be a boolean flag,
+// otherwise the name is returned as it is.
+func (f *FlagSet) splitShorts(name string) []string {
+	runes := []rune(name)
+	if len(runes) < 2 {
+		return []string{name}
+	}
+	names := make([]string, len(runes))
+	for i, r := range runes {
+		short := string(r)
+		fl := f.Lookup(short)
+		if fl == nil || f.Short(fl.Name) != short || (i < len(runes)-1 && !isBoolFlag(fl)) {
+			return []string{name}
+		}
+		names[i] = short
+	}
+	return names
+}
+
+// negated returns the boolean flag for a "no-" prefixed name, if the name
+// isn't defined as a flag itself.
+func (f *FlagSet) negated(name string) *flag.Flag {
+	if !strings.HasPrefix(name, "no-") {
+		return nil
+	}
+	if fl := f.Lookup(strings.TrimPrefix(name, "no-")); fl != nil && isBoolFlag(fl) {
+		return fl
+	}
+	return nil
+}
+
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface {
+		IsBoolFlag() bool
+	})
+	return ok && b.IsBoolFlag()
+}
@@ -0,0 +1,492 @@
+package flagset
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// timeNow returns the current time, which relative times are based on.
+var timeNow = time.Now
+
+// SizeVar defines a byte size flag with specified name, default value, and
+// usage string. Sizes are passed as a number with an optional unit, such as
+// "512", "10MiB" or "1.5G". Units with an "i", such as "KiB", are powers of
+// 1024, the others are powers of 1000.
+// The argument p points to a uint64 variable in which to store the number of
+// bytes.
+func (f *FlagSet) SizeVar(p *uint64, name string, value uint64, usage string) {
+	f.flag.Var(newSizeValue(value, p), name, usage)
+}
+
+// SizeVarP is like SizeVar, but also accepts a short name that can be used
+// instead of the name.
+func (f *FlagSet) SizeVarP(p *uint64, name, short string, value uint64, usage string) {
+	f.SizeVar(p, name, value, usage)
+	f.alias(short, name)
+}
+
+// Size defines a byte size flag with specified name, default value, and usage
+// string.
+// The return value is the address of a uint64 variable that stores the number
+// of bytes.
+func (f *FlagSet) Size(name string, value uint64, usage string) *uint64 {
+	p := new(uint64)
+	f.SizeVar(p, name, value, usage)
+	return p
+}
+
+// TimeVar defines a time flag with specified name, default value, and usage
+// string. Times are passed as RFC3339, such as "2006-01-02T15:04:05Z", or as
+// a duration relative to now, such as "-2h" or "+30m".
+// The argument p points to a time.Time variable in which to store the value of
+// the flag.
+func (f *FlagSet) TimeVar(p *time.Time, name string, value time.Time, usage string) {
+	f.flag.Var(newTimeValue(value, p), name, usage)
+}
+
+// TimeVarP is like TimeVar, but also accepts a short name that can be used
+// instead of the name.
+func (f *FlagSet) TimeVarP(p *time.Time, name, short string, value time.Time, usage string) {
+	f.TimeVar(p, name, value, usage)
+	f.alias(short, name)
+}
+
+// Time defines a time flag with specified name, default value, and usage
+// string.
+// The return value is the address of a time.Time variable that stores the
+// value of the flag.
+func (f *FlagSet) Time(name string, value time.Time, usage string) *time.Time {
+	p := new(time.Time)
+	f.TimeVar(p, name, value, usage)
+	return p
+}
+
+// URLVar defines a URL flag with specified name, default value, and usage
+// string. The URL must be absolute, with a scheme and a host.
+// The argument p points to a url.URL variable in which to store the value of
+// the flag.
+func (f *FlagSet) URLVar(p *url.URL, name string, value *url.URL, usage string) {
+	f.flag.Var(newURLValue(value, p), name, usage)
+}
+
+// URLVarP is like URLVar, but also accepts a short name that can be used
+// instead of the name.
+func (f *FlagSet) URLVarP(p *url.URL, name, short string, value *url.URL, usage string) {
+	f.URLVar(p, name, value, usage)
+	f.alias(short, name)
+}
+
+// URL defines a URL flag with specified name, default value, and usage string.
+// The return value is the address of a url.URL variable that stores the value
+// of the flag.
+func (f *FlagSet) URL(name string, value *url.URL, usage string) *url.URL {
+	p := new(url.URL)
+	f.URLVar(p, name, value, usage)
+	return p
+}
+
+// HostPortVar defines a "host:port" flag with specified name, default value,
+// and usage string.
+// The argument p points to a string variable in which to store the value of
+// the flag.
+func (f *FlagSet) HostPortVar(p *string, name string, value string, usage string) {
+	f.flag.Var(newHostPortValue(value, p), name, usage)
+}
+
+// HostPortVarP is like HostPortVar, but also accepts a short name that can be
+// used instead of the name.
+func (f *FlagSet) HostPortVarP(p *string, name, short string, value string, usage string) {
+	f.HostPortVar(p, name, value, usage)
+	f.alias(short, name)
+}
+
+// HostPort defines a "host:port" flag with specified name, default value, and
+// usage string.
+// The return value is the address of a string variable that stores the value
+// of the flag.
+func (f *FlagSet) HostPort(name string, value string, usage string) *string {
+	p := new(string)
+	f.HostPortVar(p, name, value, usage)
+	return p
+}
+
+// IPVar defines an IP address flag with specified name, default value, and
+// usage string.
+// The argument p points to a net.IP variable in which to store the value of
+// the flag.
+func (f *FlagSet) IPVar(p *net.IP, name string, value net.IP, usage string) {
+	f.flag.Var(newIPValue(value, p), name, usage)
+}
+
+// IPVarP is like IPVar, but also accepts a short name that can be used
+// instead of the name.
+func (f *FlagSet) IPVarP(p *net.IP, name, short string, value net.IP, usage string) {
+	f.IPVar(p, name, value, usage)
+	f.alias(short, name)
+}
+
+// IP defines an IP address flag with specified name, default value, and usage
+// string.
+// The return value is the address of a net.IP variable that stores the value
+// of the flag.
+func (f *FlagSet) IP(name string, value net.IP, usage string) *net.IP {
+	p := new(net.IP)
+	f.IPVar(p, name, value, usage)
+	return p
+}
+
+// CIDRVar defines a CIDR flag with specified name, default value, and usage
+// string, such as "10.0.0.0/8".
+// The argument p points to a net.IPNet variable in which to store the value of
+// the flag.
+func (f *FlagSet) CIDRVar(p *net.IPNet, name string, value *net.IPNet, usage string) {
+	f.flag.Var(newCIDRValue(value, p), name, usage)
+}
+
+// CIDRVarP is like CIDRVar, but also accepts a short name that can be used
+// instead of the name.
+func (f *FlagSet) CIDRVarP(p *net.IPNet, name, short string, value *net.IPNet, usage string) {
+	f.CIDRVar(p, name, value, usage)
+	f.alias(short, name)
+}
+
+// CIDR defines a CIDR flag with specified name, default value, and usage
+// string.
+// The return value is the address of a net.IPNet variable that stores the
+// value of the flag.
+func (f *FlagSet) CIDR(name string, value *net.IPNet, usage string) *net.IPNet {
+	p := new(net.IPNet)
+	f.CIDRVar(p, name, value, usage)
+	return p
+}
+
+type sizeUnit struct {
+	suffix string
+	size   uint64
+}
+
+// sizeUnits are ordered from the largest unit to the smallest, with the
+// binary units first, so that sizes are formatted with the largest exact unit.
+var sizeUnits = []sizeUnit{
+	{"PiB", 1 << 50},
+	{"TiB", 1 << 40},
+	{"GiB", 1 << 30},
+	{"MiB", 1 << 20},
+	{"KiB", 1 << 10},
+	{"PB", 1e15},
+	{"TB", 1e12},
+	{"GB", 1e9},
+	{"MB", 1e6},
+	{"KB", 1e3},
+}
+
+// ParseSize parses a byte size, such as "10MiB" or "1.5G", into the number of
+// bytes. The "B" of the unit is optional and the unit is case insensitive.
+// Returns an error if the size isn't a whole number of bytes, or is too large.
+func ParseSize(s string) (uint64, error) {
+	value := strings.TrimSpace(s)
+	idx := strings.IndexFunc(value, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	number, unit := value, ""
+	if idx >= 0 {
+		number, unit = value[:idx], strings.TrimSpace(value[idx:])
+	}
+
+	// The number is parsed exactly, so that fractions of a byte are found.
+	n, ok := new(big.Rat).SetString(number)
+	if !ok || number == "" {
+		return 0, errors.Errorf("invalid size %q", s)
+	}
+
+	multiplier := uint64(1)
+	if unit = strings.TrimSuffix(strings.ToUpper(unit), "B"); unit != "" {
+		var found bool
+		for _, u := range sizeUnits {
+			if strings.TrimSuffix(strings.ToUpper(u.suffix), "B") == unit {
+				multiplier, found = u.size, true
+				break
+			}
+		}
+		if !found {
+			return 0, errors.Errorf("invalid size %q: unknown unit %q", s, unit)
+		}
+	}
+	n.Mul(n, new(big.Rat).SetInt(new(big.Int).SetUint64(multiplier)))
+	if !n.IsInt() {
+		return 0, errors.Errorf("invalid size %q: not a whole number of bytes", s)
+	}
+	if !n.Num().IsUint64() {
+		return 0, errors.Errorf("invalid size %q: too large", s)
+	}
+	return n.Num().Uint64(), nil
+}
+
+// FormatSize formats the number of bytes with the largest unit that the size
+// is an exact multiple of, such as "10MiB".
+func FormatSize(size uint64) string {
+	if size == 0 {
+		return "0B"
+	}
+	for _, u := range sizeUnits {
+		if size%u.size == 0 {
+			return fmt.Sprintf("%d%s", size/u.size, u.suffix)
+		}
+	}
+	return fmt.Sprintf("%dB", size)
+}
+
+type sizeValue struct {
+	value *uint64
+}
+
+func newSizeValue(val uint64, p *uint64) *sizeValue {
+	*p = val
+	return &sizeValue{
+		value: p,
+	}
+}
+
+func (s *sizeValue) Set(val string) error {
+	size, err := ParseSize(val)
+	if err != nil {
+		return err
+	}
+	*s.value = size
+	return nil
+}
+
+func (s *sizeValue) Get() interface{} {
+	return *s.value
+}
+
+func (s *sizeValue) Type() string {
+	return "size"
+}
+
+func (s *sizeValue) String() string {
+	if s.value == nil {
+		return ""
+	}
+	return FormatSize(*s.value)
+}
+
+type timeValue struct {
+	value *time.Time
+}
+
+func newTimeValue(val time.Time, p *time.Time) *timeValue {
+	*p = val
+	return &timeValue{
+		value: p,
+	}
+}
+
+func (s *timeValue) Set(val string) error {
+	if val == "" {
+		*s.value = time.Time{}
+		return nil
+	}
+	if strings.HasPrefix(val, "-") || strings.HasPrefix(val, "+") {
+		d, err := time.ParseDuration(val)
+		if err != nil {
+			return errors.Errorf("invalid relative time %q", val)
+		}
+		*s.value = timeNow().Add(d)
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, val)
+	if err != nil {
+		return errors.Errorf("invalid time %q, expected RFC3339 or a relative duration", val)
+	}
+	*s.value = t
+	return nil
+}
+
+func (s *timeValue) Get() interface{} {
+	return *s.value
+}
+
+func (s *timeValue) Type() string {
+	return "time"
+}
+
+func (s *timeValue) String() string {
+	if s.value == nil || s.value.IsZero() {
+		return ""
+	}
+	return s.value.Format(time.RFC3339)
+}
+
+type urlValue struct {
+	value *url.URL
+}
+
+func newURLValue(val *url.URL, p *url.URL) *urlValue {
+	if val != nil {
+		*p = *val
+	}
+	return &urlValue{
+		value: p,
+	}
+}
+
+func (s *urlValue) Set(val string) error {
+	if val == "" {
+		*s.value = url.URL{}
+		return nil
+	}
+	u, err := url.Parse(val)
+	if err != nil {
+		return errors.Errorf("invalid url %q", val)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return errors.Errorf("invalid url %q, expected a scheme and host", val)
+	}
+	*s.value = *u
+	return nil
+}
+
+func (s *urlValue) Get() interface{} {
+	return *s.value
+}
+
+func (s *urlValue) Type() string {
+	return "url"
+}
+
+func (s *urlValue) String() string {
+	if s.value == nil {
+		return ""
+	}
+	return s.value.String()
+}
+
+type hostPortValue struct {
+	value *string
+}
+
+func newHostPortValue(val string, p *string) *hostPortValue {
+	*p = val
+	return &hostPortValue{
+		value: p,
+	}
+}
+
+func (s *hostPortValue) Set(val string) error {
+	if val == "" {
+		*s.value = val
+		return nil
+	}
+	_, port, err := net.SplitHostPort(val)
+	if err != nil {
+		return errors.Errorf("invalid host:port %q", val)
+	}
+	if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+		return errors.Errorf("invalid port %q", port)
+	}
+	*s.value = val
+	return nil
+}
+
+func (s *hostPortValue) Get() interface{} {
+	return *s.value
+}
+
+func (s *hostPortValue) Type() string {
+	return "host:port"
+}
+
+func (s *hostPortValue) String() string {
+	if s.value == nil {
+		return ""
+	}
+	return *s.value
+}
+
+type ipValue struct {
+	value *net.IP
+}
+
+func newIPValue(val net.IP, p *net.IP) *ipValue {
+	*p = val
+	return &ipValue{
+		value: p,
+	}
+}
+
+func (s *ipValue) Set(val string) error {
+	if val == "" {
+		*s.value = nil
+		return nil
+	}
+	ip := net.ParseIP(val)
+	if ip == nil {
+		return errors.Errorf("invalid ip %q", val)
+	}
+	*s.value = ip
+	return nil
+}
+
+func (s *ipValue) Get() interface{} {
+	return *s.value
+}
+
+func (s *ipValue) Type() string {
+	return "ip"
+}
+
+func (s *ipValue) String() string {
+	if s.value == nil || *s.value == nil {
+		return ""
+	}
+	return s.value.String()
+}
+
+type cidrValue struct {
+	value *net.IPNet
+}
+
+func newCIDRValue(val *net.IPNet, p *net.IPNet) *cidrValue {
+	if val != nil {
+		*p = *val
+	}
+	return &cidrValue{
+		value: p,
+	}
+}
+
+func (s *cidrValue) Set(val string) error {
+	if val == "" {
+		*s.value = net.IPNet{}
+		return nil
+	}
+	_, ipNet, err := net.ParseCIDR(val)
+	if err != nil {
+		return errors.Errorf("invalid cidr %q", val)
+	}
+	*s.value = *ipNet
+	return nil
+}
+
+func (s *cidrValue) Get() interface{} {
+	return *s.value
+}
+
+func (s *cidrValue) Type() string {
+	return "cidr"
+}
+
+func (s *cidrValue) String() string {
+	if s.value == nil || s.value.IP == nil {
+		return ""
+	}
+	return s.value.String()
+}
@@ -0,0 +1,268 @@
+package flagset
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spoke-d/clui/autocomplete/args"
+)
+
+// StringSliceVar defines a string slice flag with specified name, default
+// value, and usage string. The flag can be repeated, or passed a comma
+// separated list, to add more values. The first value replaces the default.
+// The argument p points to a []string variable in which to store the value of
+// the flag.
+func (f *FlagSet) StringSliceVar(p *[]string, name string, value []string, usage string) {
+	f.flag.Var(newStringSliceValue(value, p), name, usage)
+}
+
+// StringSliceVarP is like StringSliceVar, but also accepts a short name that
+// can be used instead of the name.
+func (f *FlagSet) StringSliceVarP(p *[]string, name, short string, value []string, usage string) {
+	f.StringSliceVar(p, name, value, usage)
+	f.alias(short, name)
+}
+
+// StringSlice defines a string slice flag with specified name, default value,
+// and usage string.
+// The return value is the address of a []string variable that stores the
+// value of the flag.
+func (f *FlagSet) StringSlice(name string, value []string, usage string) *[]string {
+	p := new([]string)
+	f.StringSliceVar(p, name, value, usage)
+	return p
+}
+
+// StringMapVar defines a map flag with specified name, default value, and
+// usage string. Values are passed as "key=value", and the flag can be
+// repeated, or passed a comma separated list, to add more keys. The first
+// value replaces the default.
+// The argument p points to a map[string]string variable in which to store the
+// value of the flag.
+func (f *FlagSet) StringMapVar(p *map[string]string, name string, value map[string]string, usage string) {
+	f.flag.Var(newStringMapValue(value, p), name, usage)
+}
+
+// StringMapVarP is like StringMapVar, but also accepts a short name that can
+// be used instead of the name.
+func (f *FlagSet) StringMapVarP(p *map[string]string, name, short string, value map[string]string, usage string) {
+	f.StringMapVar(p, name, value, usage)
+	f.alias(short, name)
+}
+
+// StringMap defines a map flag with specified name, default value, and usage
+// string.
+// The return value is the address of a map[string]string variable that stores
+// the value of the flag.
+func (f *FlagSet) StringMap(name string, value map[string]string, usage string) *map[string]string {
+	p := new(map[string]string)
+	f.StringMapVar(p, name, value, usage)
+	return p
+}
+
+// EnumVar defines a string flag with specified name, default value, and usage
+// string, that only accepts one of the choices. The choices are used to
+// predict the value of the flag.
+// The argument p points to a string variable in which to store the value of
+// the flag.
+func (f *FlagSet) EnumVar(p *string, name string, value string, choices []string, usage string) {
+	f.flag.Var(newEnumValue(value, choices, p), name, usage)
+	f.SetPredictor(name, args.PredictSet(choices...))
+}
+
+// EnumVarP is like EnumVar, but also accepts a short name that can be used
+// instead of the name.
+func (f *FlagSet) EnumVarP(p *string, name, short string, value string, choices []string, usage string) {
+	f.EnumVar(p, name, value, choices, usage)
+	f.alias(short, name)
+}
+
+// Enum defines a string flag with specified name, default value, and usage
+// string, that only accepts one of the choices.
+// The return value is the address of a string variable that stores the value
+// of the flag.
+func (f *FlagSet) Enum(name string, value string, choices []string, usage string) *string {
+	p := new(string)
+	f.EnumVar(p, name, value, choices, usage)
+	return p
+}
+
+// Choices returns the choices of the named enum flag.
+// Returns false if the flag isn't an enum flag.
+func (f *FlagSet) Choices(name string) ([]string, bool) {
+	fl := f.Lookup(name)
+	if fl == nil {
+		return nil, false
+	}
+	v, ok := fl.Value.(*enumValue)
+	if !ok {
+		return nil, false
+	}
+	return v.choices, true
+}
+
+// resetter is implemented by values that add to the default when they're set
+// more than once, so that they can be set back to the default before parsing.
+type resetter interface {
+	reset()
+}
+
+type stringSliceValue struct {
+	value   *[]string
+	def     []string
+	changed bool
+}
+
+func newStringSliceValue(val []string, p *[]string) *stringSliceValue {
+	s := &stringSliceValue{
+		value: p,
+		def:   append([]string(nil), val...),
+	}
+	s.reset()
+	return s
+}
+
+func (s *stringSliceValue) reset() {
+	*s.value = append([]string(nil), s.def...)
+	s.changed = false
+}
+
+func (s *stringSliceValue) Set(val string) error {
+	var values []string
+	if val != "" {
+		values = strings.Split(val, ",")
+	}
+	if !s.changed {
+		*s.value = values
+		s.changed = true
+		return nil
+	}
+	*s.value = append(*s.value, values...)
+	return nil
+}
+
+func (s *stringSliceValue) Get() interface{} {
+	return *s.value
+}
+
+func (s *stringSliceValue) Type() string {
+	return "strings"
+}
+
+func (s *stringSliceValue) String() string {
+	if s.value == nil {
+		return ""
+	}
+	return strings.Join(*s.value, ",")
+}
+
+type stringMapValue struct {
+	value   *map[string]string
+	def     map[string]string
+	changed bool
+}
+
+func newStringMapValue(val map[string]string, p *map[string]string) *stringMapValue {
+	s := &stringMapValue{
+		value: p,
+		def:   val,
+	}
+	s.reset()
+	return s
+}
+
+func (s *stringMapValue) reset() {
+	*s.value = make(map[string]string, len(s.def))
+	for k, v := range s.def {
+		(*s.value)[k] = v
+	}
+	s.changed = false
+}
+
+func (s *stringMapValue) Set(val string) error {
+	values := make(map[string]string)
+	if val != "" {
+		for _, pair := range strings.Split(val, ",") {
+			parts := strings.SplitN(pair, "=", 2)
+			if len(parts) != 2 || parts[0] == "" {
+				return errors.Errorf("expected key=value, got %q", pair)
+			}
+			values[parts[0]] = parts[1]
+		}
+	}
+	if !s.changed {
+		*s.value = values
+		s.changed = true
+		return nil
+	}
+	for k, v := range values {
+		(*s.value)[k] = v
+	}
+	return nil
+}
+
+func (s *stringMapValue) Get() interface{} {
+	return *s.value
+}
+
+func (s *stringMapValue) Type() string {
+	return "key=value"
+}
+
+func (s *stringMapValue) String() string {
+	if s.value == nil {
+		return ""
+	}
+	pairs := make([]string, 0, len(*s.value))
+	for k, v := range *s.value {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+type enumValue struct {
+	value   *string
+	def     string
+	choices []string
+}
+
+func newEnumValue(val string, choices []string, p *string) *enumValue {
+	*p = val
+	return &enumValue{
+		value:   p,
+		def:     val,
+		choices: choices,
+	}
+}
+
+func (s *enumValue) Set(val string) error {
+	// The default is always valid, so that the flag can be reset.
+	if val == s.def {
+		*s.value = val
+		return nil
+	}
+	for _, choice := range s.choices {
+		if val == choice {
+			*s.value = val
+			return nil
+		}
+	}
+	return errors.Errorf("must be one of %s", strings.Join(s.choices, ", "))
+}
+
+func (s *enumValue) Get() interface{} {
+	return *s.value
+}
+
+func (s *enumValue) Type() string {
+	return "string"
+}
+
+func (s *enumValue) String() string {
+	if s.value == nil {
+		return ""
+	}
+	return *s.value
+}
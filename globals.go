@@ -0,0 +1,229 @@
+package clui
+
+import (
+	"flag"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spoke-d/clui/autocomplete/args"
+	"github.com/spoke-d/clui/flagset"
+	"github.com/spoke-d/clui/help"
+	"github.com/spoke-d/clui/ui"
+)
+
+const (
+	flagHelp                  = "help"
+	flagVersion               = "version"
+	flagDebug                 = "debug"
+	flagConfig                = "config"
+	flagFormat                = "format"
+	flagDevMode               = "dev-mode"
+	flagNoColor               = "no-color"
+	flagNoSubKeys             = "no-sub-keys"
+	flagAutoCompleteInstall   = "autocomplete-install"
+	flagAutoCompleteUninstall = "autocomplete-uninstall"
+)
+
+// GlobalFlags is a registry of flags that are available to every command.
+// Global flags are parsed before the sub command is resolved, so they can be
+// placed anywhere in the arguments. A flag of the command with the same name
+// takes precedence when it's passed after the command.
+type GlobalFlags struct {
+	flagSet *flagset.FlagSet
+	hidden  map[string]struct{}
+}
+
+// NewGlobalFlags creates a GlobalFlags registry with the built in global flags
+// already registered.
+func NewGlobalFlags() *GlobalFlags {
+	g := &GlobalFlags{
+		flagSet: flagset.New("global", flag.ContinueOnError),
+		hidden: map[string]struct{}{
+			flagDevMode:               {},
+			flagNoColor:               {},
+			flagNoSubKeys:             {},
+			flagAutoCompleteInstall:   {},
+			flagAutoCompleteUninstall: {},
+		},
+	}
+	g.flagSet.BoolVarP(new(bool), flagHelp, "h", false, "Print command help")
+	g.flagSet.BoolVarP(new(bool), flagVersion, "v", false, "Print client version")
+	g.flagSet.Bool(flagDebug, false, "Show all debug messages")
+	g.flagSet.String(flagConfig, "", "Path to the config file")
+	g.flagSet.String(flagFormat, string(ui.OutputText), "Output format (text, json, yaml, table)")
+	g.flagSet.Bool(flagDevMode, false, "Run in development mode")
+	g.flagSet.Bool(flagNoColor, false, "Disable color output")
+	g.flagSet.Bool(flagNoSubKeys, false, "Hide nested commands from help")
+	g.flagSet.Bool(flagAutoCompleteInstall, false, "Install autocomplete")
+	g.flagSet.Bool(flagAutoCompleteUninstall, false, "Uninstall autocomplete")
+
+	formats := make([]string, 0, len(ui.OutputFormats()))
+	for _, format := range ui.OutputFormats() {
+		formats = append(formats, string(format))
+	}
+	g.flagSet.SetPredictor(flagConfig, args.PredictFiles(""))
+	g.flagSet.SetPredictor(flagFormat, args.PredictSet(formats...))
+	return g
+}
+
+// FlagSet returns the FlagSet for registering new global flags.
+func (g *GlobalFlags) FlagSet() *flagset.FlagSet {
+	return g.flagSet
+}
+
+// Help returns all the global flags that should be shown in the help output,
+// sorted by name.
+func (g *GlobalFlags) Help() []help.GlobalFlag {
+	var flags []help.GlobalFlag
+	g.flagSet.VisitAll(func(f *flag.Flag) {
+		if _, ok := g.hidden[f.Name]; ok {
+			return
+		}
+		flags = append(flags, help.GlobalFlag{
+			Name:  f.Name,
+			Short: g.flagSet.Short(f.Name),
+			Usage: f.Usage,
+		})
+	})
+	sort.Slice(flags, func(i, j int) bool {
+		return flags[i].Name < flags[j].Name
+	})
+	return flags
+}
+
+// Parse consumes all the global flags from the arguments, returning the
+// arguments that remain. Flags after "--" are left as they are. Global flags
+// are reset to their defaults before parsing.
+// Returns an error if a global flag has an invalid value.
+func (g *GlobalFlags) Parse(args []string) ([]string, error) {
+	return g.parse(args, nil)
+}
+
+// Shadowed returns the names of the global flags, including the short names,
+// that are also defined by the command flags. The values are true if the
+// command flag takes a value.
+func (g *GlobalFlags) Shadowed(flags *flagset.FlagSet) map[string]bool {
+	shadowed := make(map[string]bool)
+	g.flagSet.VisitAll(func(f *flag.Flag) {
+		for _, name := range []string{f.Name, g.flagSet.Short(f.Name)} {
+			if name == "" {
+				continue
+			}
+			if c := flags.Lookup(name); c != nil {
+				shadowed[name] = !isBoolFlag(c)
+			}
+		}
+	})
+	return shadowed
+}
+
+// all returns the names of all the global flags, including the short names,
+// so that they can all be shadowed.
+func (g *GlobalFlags) all() map[string]bool {
+	shadowed := make(map[string]bool)
+	g.flagSet.VisitAll(func(f *flag.Flag) {
+		shadowed[f.Name] = false
+		if short := g.flagSet.Short(f.Name); short != "" {
+			shadowed[short] = false
+		}
+	})
+	return shadowed
+}
+
+// parse consumes the global flags from the arguments. The shadowed flags are
+// left for the command when they're passed after the first command word, along
+// with their value if they take one.
+func (g *GlobalFlags) parse(args []string, shadowed map[string]bool) ([]string, error) {
+	g.flagSet.Reset()
+
+	var (
+		remaining []string
+		command   bool
+	)
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			// Everything after belongs to the command.
+			remaining = append(remaining, args[i:]...)
+			break
+		}
+
+		name, value, ok := splitFlag(arg)
+		if !ok {
+			remaining = append(remaining, arg)
+			command = true
+			continue
+		}
+		if takesValue, ok := shadowed[name]; ok && command {
+			remaining = append(remaining, arg)
+			if takesValue && value == nil && i+1 < len(args) {
+				i++
+				remaining = append(remaining, args[i])
+			}
+			continue
+		}
+		f := g.flagSet.Lookup(name)
+		if f == nil {
+			remaining = append(remaining, arg)
+			continue
+		}
+
+		if value == nil {
+			if isBoolFlag(f) {
+				v := "true"
+				value = &v
+			} else {
+				if i+1 >= len(args) {
+					return nil, errors.Errorf("flag needs an argument: --%s", f.Name)
+				}
+				i++
+				value = &args[i]
+			}
+		}
+		if err := f.Value.Set(*value); err != nil {
+			return nil, errors.Wrapf(err, "invalid value %q for flag --%s", *value, f.Name)
+		}
+	}
+	return remaining, nil
+}
+
+func (g *GlobalFlags) bool(name string) bool {
+	f := g.flagSet.Lookup(name)
+	if f == nil {
+		return false
+	}
+	return f.Value.String() == "true"
+}
+
+func (g *GlobalFlags) string(name string) string {
+	f := g.flagSet.Lookup(name)
+	if f == nil {
+		return ""
+	}
+	return f.Value.String()
+}
+
+// splitFlag splits an argument into a flag name and an optional value.
+// Returns false if the argument isn't a flag.
+func splitFlag(arg string) (string, *string, bool) {
+	if len(arg) < 2 || arg[0] != '-' {
+		return "", nil, false
+	}
+	name := strings.TrimPrefix(arg[1:], "-")
+	if name == "" || name[0] == '-' || name[0] == '=' {
+		return "", nil, false
+	}
+	if idx := strings.Index(name, "="); idx >= 0 {
+		value := name[idx+1:]
+		return name[:idx], &value, true
+	}
+	return name, nil, true
+}
+
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface {
+		IsBoolFlag() bool
+	})
+	return ok && b.IsBoolFlag()
+}
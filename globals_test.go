@@ -0,0 +1,236 @@
+package clui
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/spoke-d/clui/group"
+	"github.com/spoke-d/clui/help"
+	"github.com/spoke-d/clui/ui"
+)
+
+func TestGlobalFlags(t *testing.T) {
+	t.Parallel()
+
+	t.Run("parse", func(t *testing.T) {
+		globals := NewGlobalFlags()
+		profile := globals.FlagSet().String("profile", "default", "Profile to use")
+		output := globals.FlagSet().String("output", "text", "Output format")
+
+		remaining, err := globals.Parse([]string{"--profile", "prod", "config", "--output=json", "--other"})
+		if expected, actual := true, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v, err: %v", expected, actual, err)
+		}
+		if expected, actual := []string{"config", "--other"}, remaining; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := "prod", *profile; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := "json", *output; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("parse shadowed", func(t *testing.T) {
+		globals := NewGlobalFlags()
+
+		remaining, err := globals.parse([]string{
+			"--format", "json", "cp", "--format", "csv", "-v", "src", "--debug",
+		}, map[string]bool{"format": true, "v": false})
+		if expected, actual := true, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v, err: %v", expected, actual, err)
+		}
+		if expected, actual := []string{"cp", "--format", "csv", "-v", "src"}, remaining; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := "json", globals.string(flagFormat); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := false, globals.bool(flagVersion); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := true, globals.bool(flagDebug); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("parse double dash", func(t *testing.T) {
+		globals := NewGlobalFlags()
+
+		remaining, err := globals.Parse([]string{"--debug", "cp", "--", "--format", "csv"})
+		if expected, actual := true, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v, err: %v", expected, actual, err)
+		}
+		if expected, actual := []string{"cp", "--", "--format", "csv"}, remaining; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := "text", globals.string(flagFormat); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("parse resets", func(t *testing.T) {
+		globals := NewGlobalFlags()
+		profile := globals.FlagSet().String("profile", "default", "Profile to use")
+
+		if _, err := globals.Parse([]string{"--profile", "prod", "--debug"}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := globals.Parse([]string{}); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := "default", *profile; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := false, globals.bool(flagDebug); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("parse resets slices", func(t *testing.T) {
+		globals := NewGlobalFlags()
+		tags := globals.FlagSet().StringSlice("tag", []string{"a"}, "Tags")
+
+		for _, testcase := range []struct {
+			args []string
+			want []string
+		}{
+			{[]string{"--tag", "b"}, []string{"b"}},
+			{[]string{"--tag", "a", "--tag", "c"}, []string{"a", "c"}},
+			{[]string{}, []string{"a"}},
+		} {
+			if _, err := globals.Parse(testcase.args); err != nil {
+				t.Fatal(err)
+			}
+			if expected, actual := testcase.want, *tags; !reflect.DeepEqual(expected, actual) {
+				t.Errorf("expected: %v, actual: %v", expected, actual)
+			}
+		}
+	})
+
+	t.Run("parse short", func(t *testing.T) {
+		globals := NewGlobalFlags()
+
+		if _, err := globals.Parse([]string{"-h", "-v"}); err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := true, globals.bool(flagHelp); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := true, globals.bool(flagVersion); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("parse missing value", func(t *testing.T) {
+		globals := NewGlobalFlags()
+		globals.FlagSet().String("profile", "default", "Profile to use")
+
+		_, err := globals.Parse([]string{"config", "--profile"})
+		if expected, actual := "flag needs an argument: --profile", err.Error(); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("help", func(t *testing.T) {
+		globals := NewGlobalFlags()
+		globals.FlagSet().String("profile", "default", "Profile to use")
+
+		want := []help.GlobalFlag{
+			{Name: "config", Usage: "Path to the config file"},
+			{Name: "debug", Usage: "Show all debug messages"},
+			{Name: "format", Usage: "Output format (text, json, yaml, table)"},
+			{Name: "help", Short: "h", Usage: "Print command help"},
+			{Name: "profile", Usage: "Profile to use"},
+			{Name: "version", Short: "v", Usage: "Print client version"},
+		}
+		if expected, actual := want, globals.Help(); !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("global args", func(t *testing.T) {
+		globals := NewGlobalFlags()
+		profile := globals.FlagSet().String("profile", "default", "Profile to use")
+
+		group := group.New()
+		group.Add("a b", nil)
+
+		args := NewGlobalArgs(group, OptionGlobalFlags(globals))
+		err := args.Process([]string{"a", "b", "c", "--profile", "prod"})
+		if expected, actual := true, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v, err: %v", expected, actual, err)
+		}
+		if expected, actual := "a b", args.SubCommand(); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := []string{"c"}, args.SubCommandArgs(); !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := "prod", *profile; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+}
+
+func TestOutputFormat(t *testing.T) {
+	t.Parallel()
+
+	t.Run("format", func(t *testing.T) {
+		var buf bytes.Buffer
+		cli := New("cli", "1.0.0", "",
+			OptionUI(ui.NewBasicUI(nil, &buf, &buf)),
+			OptionAutoCompleter(nopAutoCompleter{}),
+		)
+
+		code, err := cli.Run([]string{"--version", "--format", "yaml"})
+		if expected, actual := true, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v, err: %v", expected, actual, err)
+		}
+		if expected, actual := EOK, code; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := "Version: 1.0.0\n", buf.String(); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("command format", func(t *testing.T) {
+		var buf bytes.Buffer
+		cli := New("cli", "1.0.0", "",
+			OptionStdio(nil, &buf, &buf),
+			OptionAutoCompleter(nopAutoCompleter{}),
+			OptionEnv(lookupEnv(nil)),
+		)
+		cli.Add("greet", greetCmdFn)
+
+		code, err := cli.Run([]string{"greet", "--format=json"})
+		if expected, actual := true, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v, err: %v", expected, actual, err)
+		}
+		if expected, actual := EOK, code; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := "{\n  \"name\": \"world\"\n}\n", buf.String(); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		var buf bytes.Buffer
+		cli := New("cli", "1.0.0", "",
+			OptionUI(ui.NewBasicUI(nil, &buf, &buf)),
+			OptionAutoCompleter(nopAutoCompleter{}),
+		)
+
+		code, err := cli.Run([]string{"--version", "--format=xml"})
+		if expected, actual := `unsupported output format "xml"`, err.Error(); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := EPerm, code; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+}
@@ -3,6 +3,7 @@ module github.com/spoke-d/clui
 go 1.13
 
 require (
+	github.com/BurntSushi/toml v0.3.1
 	github.com/chzyer/logex v1.1.10 // indirect
 	github.com/chzyer/readline v0.0.0-20180603132655-2972be24d48e
 	github.com/chzyer/test v0.0.0-20180213035817-a1ea475d72b1 // indirect
@@ -11,4 +12,5 @@ require (
 	github.com/pkg/errors v0.9.1
 	github.com/spoke-d/task v0.0.0-20200611082300-0956eca7ba1a
 	golang.org/x/crypto v0.0.0-20200109152110-61a87790db17
+	gopkg.in/yaml.v2 v2.4.0
 )
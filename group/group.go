@@ -3,6 +3,7 @@ package group
 import (
 	"fmt"
 	"math"
+	"sort"
 	"strings"
 
 	"github.com/spoke-d/clui/commands"
@@ -75,11 +76,70 @@ func OptionPlaceHolder(i PlaceHolder) GroupOption {
 	}
 }
 
+// CommandOptions represents a way to set optional values to a command when
+// it's added to the group.
+// The CommandOptions shows what options are available to change.
+type CommandOptions interface {
+	AppendAliases(...string)
+	SetHidden(bool)
+	SetDeprecated(string)
+}
+
+// CommandOption captures a tweak that can be applied to a command when it's
+// added to the Group.
+type CommandOption func(CommandOptions)
+
+type command struct {
+	aliases    []string
+	hidden     bool
+	deprecated string
+}
+
+func (s *command) AppendAliases(p ...string) {
+	s.aliases = append(s.aliases, p...)
+}
+
+func (s *command) SetHidden(p bool) {
+	s.hidden = p
+}
+
+func (s *command) SetDeprecated(p string) {
+	s.deprecated = p
+}
+
+// OptionAliases allows the setting of alternative names for a command. Each
+// alias replaces the last name of the command key, so an alias of "ls" for
+// "config list" can be called as "config ls".
+func OptionAliases(i ...string) CommandOption {
+	return func(opt CommandOptions) {
+		opt.AppendAliases(i...)
+	}
+}
+
+// OptionHidden allows the setting of a hidden option to configure the command.
+// Hidden commands can still be run, but aren't shown in any listings.
+func OptionHidden() CommandOption {
+	return func(opt CommandOptions) {
+		opt.SetHidden(true)
+	}
+}
+
+// OptionDeprecated allows the setting of a deprecated option to configure the
+// command. The replacement is the command key that should be used instead.
+func OptionDeprecated(replacement string) CommandOption {
+	return func(opt CommandOptions) {
+		opt.SetDeprecated(replacement)
+	}
+}
+
 // Group holds the commands in a central repository for easy access.
 type Group struct {
 	commands      map[string]Command
 	commandTree   *radix.Tree
 	placeholderFn PlaceHolder
+	aliases       map[string]string
+	hidden        map[string]struct{}
+	deprecated    map[string]string
 }
 
 // New creates a Group with sane defaults.
@@ -93,19 +153,57 @@ func New(options ...GroupOption) *Group {
 		commands:      make(map[string]Command),
 		commandTree:   radix.New(),
 		placeholderFn: opt.placeHolder,
+		aliases:       make(map[string]string),
+		hidden:        make(map[string]struct{}),
+		deprecated:    make(map[string]string),
 	}
 }
 
 // Add a Command to the Group for a given key. The key is normalized
 // to remove trailing spaces for consistency.
 // Returns an error when inserting into the Group fails
-func (r *Group) Add(key string, cmd Command) error {
+func (r *Group) Add(key string, cmd Command, options ...CommandOption) error {
+	opt := new(command)
+	for _, option := range options {
+		option(opt)
+	}
+
 	k := normalizeKey(key)
+	if name, ok := r.aliases[k]; ok {
+		return fmt.Errorf("key %q is already an alias of %q", k, name)
+	}
+
+	aliases := make([]string, len(opt.aliases))
+	for i, alias := range opt.aliases {
+		a, err := aliasKey(k, alias)
+		if err != nil {
+			return err
+		}
+		if _, ok := r.commands[a]; ok || a == k {
+			return fmt.Errorf("alias %q already exists", a)
+		}
+		aliases[i] = a
+	}
 
 	if _, _, err := r.commandTree.Insert(k, cmd); err != nil {
 		return err
 	}
 	r.commands[k] = cmd
+
+	if opt.hidden {
+		r.hidden[k] = struct{}{}
+	}
+	if opt.deprecated != "" {
+		r.deprecated[k] = normalizeKey(opt.deprecated)
+	}
+
+	for _, a := range aliases {
+		if _, _, err := r.commandTree.Insert(a, cmd); err != nil {
+			return err
+		}
+		r.commands[a] = cmd
+		r.aliases[a] = k
+	}
 	return nil
 }
 
@@ -119,6 +217,18 @@ func (r *Group) Remove(key string) (Command, error) {
 	if ok {
 		delete(r.commands, k)
 	}
+	delete(r.hidden, k)
+	delete(r.deprecated, k)
+
+	for a, name := range r.aliases {
+		if name == k || a == k {
+			delete(r.aliases, a)
+		}
+		if name == k {
+			delete(r.commands, a)
+			r.commandTree.Delete(a)
+		}
+	}
 
 	if _, v := r.commandTree.Delete(k); ok && v {
 		return cmd, nil
@@ -146,7 +256,60 @@ func (r *Group) Get(key string) (Command, bool) {
 	return cmd, true
 }
 
-// GetClosestName returns the closest command to the given key
+// Resolve returns the command key for a given alias. Any alias of a parent
+// command within the key is resolved as well, so "cfg show" becomes
+// "config show". If the key has no aliases, then the normalized key is
+// returned.
+func (r *Group) Resolve(key string) string {
+	names := strings.Split(normalizeKey(key), " ")
+	for i := range names {
+		// Aliases only replace the last name of the command key, so the number
+		// of names never changes.
+		if name, ok := r.aliases[strings.Join(names[:i+1], " ")]; ok {
+			copy(names, strings.Split(name, " "))
+		}
+	}
+	return strings.Join(names, " ")
+}
+
+// IsAlias returns if the given key is an alias of another command.
+func (r *Group) IsAlias(key string) bool {
+	_, ok := r.aliases[normalizeKey(key)]
+	return ok
+}
+
+// Aliases returns all the aliases for a given command key, sorted
+// lexicographically.
+func (r *Group) Aliases(key string) []string {
+	k := normalizeKey(key)
+
+	var aliases []string
+	for a, name := range r.aliases {
+		if name == k {
+			aliases = append(aliases, a)
+		}
+	}
+	sort.Strings(aliases)
+	return aliases
+}
+
+// Hidden returns if the command for a given key, or the command the alias
+// represents, should be hidden from any listings.
+func (r *Group) Hidden(key string) bool {
+	_, ok := r.hidden[r.Resolve(key)]
+	return ok
+}
+
+// Deprecated returns the replacement command key if the command for a given
+// key has been deprecated.
+// Returns true if the command is deprecated.
+func (r *Group) Deprecated(key string) (string, bool) {
+	replacement, ok := r.deprecated[r.Resolve(key)]
+	return replacement, ok
+}
+
+// GetClosestName returns the closest command to the given key. Hidden
+// commands are never returned.
 func (r *Group) GetClosestName(key string) (string, bool) {
 	if len(key) == 0 {
 		return "", false
@@ -158,6 +321,9 @@ func (r *Group) GetClosestName(key string) (string, bool) {
 		distance: math.MaxInt64,
 	}
 	r.commandTree.Walk(func(name string, value radix.Value) bool {
+		if r.Hidden(name) {
+			return false
+		}
 		d := distance.ComputeDistance(key, name)
 		if strings.HasPrefix(name, key[:1]) && d < closest.distance {
 			closest.name = name
@@ -189,7 +355,8 @@ func (r *Group) LongestPrefix(key string) (string, bool) {
 }
 
 // Process runs through the registry and fills in any commands that are required
-// for nesting (sub commands)
+// for nesting (sub commands). Persistent flags of every command are then
+// inherited by the nested sub commands.
 // Returns an error if there was an issue adding any commands to the underlying
 // storage.
 func (r *Group) Process() error {
@@ -226,10 +393,38 @@ func (r *Group) Process() error {
 				return err
 			}
 		}
+
+		r.inheritFlags()
 	}
 	return nil
 }
 
+// inheritFlags passes the persistent flags of every command to all of the
+// nested sub commands.
+func (r *Group) inheritFlags() {
+	for k, cmd := range r.commands {
+		if cmd == nil {
+			continue
+		}
+
+		// Walk up the parents, so every ancestor is inherited from.
+		parent := k
+		for {
+			idx := strings.LastIndex(parent, " ")
+			if idx == -1 {
+				break
+			}
+			parent = parent[:idx]
+
+			p, ok := r.commands[parent]
+			if !ok || p == nil || !p.FlagSet().HasPersistent() {
+				continue
+			}
+			cmd.FlagSet().Inherit(p.FlagSet())
+		}
+	}
+}
+
 // Nested returns if the commands with in the group are nested in anyway.
 func (r *Group) Nested() bool {
 	for k := range r.commands {
@@ -240,6 +435,20 @@ func (r *Group) Nested() bool {
 	return false
 }
 
+// aliasKey creates a key for an alias by replacing the last name of the
+// command key.
+// Returns an error if the alias isn't a single name.
+func aliasKey(key, alias string) (string, error) {
+	a := normalizeKey(alias)
+	if a == "" || strings.ContainsRune(a, ' ') {
+		return "", fmt.Errorf("alias %q must be a single name", alias)
+	}
+	if idx := strings.LastIndex(key, " "); idx >= 0 {
+		return key[:idx+1] + a, nil
+	}
+	return a, nil
+}
+
 // normalizeKey attempts to normalize a command key before inserting it into a
 // set of maps/trees. This should help with any possible inconsistencies when
 // querying the structure.
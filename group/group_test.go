@@ -1,6 +1,7 @@
 package group
 
 import (
+	"flag"
 	"reflect"
 	"sort"
 	"strings"
@@ -8,6 +9,7 @@ import (
 	"testing/quick"
 
 	"github.com/golang/mock/gomock"
+	"github.com/spoke-d/clui/flagset"
 	"github.com/spoke-d/clui/radix"
 )
 
@@ -97,6 +99,177 @@ func TestNewGroup(t *testing.T) {
 	})
 }
 
+func TestAliases(t *testing.T) {
+	t.Parallel()
+
+	t.Run("get", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		cmd := NewMockCommand(ctrl)
+
+		group := New()
+		err := group.Add("config list", cmd, OptionAliases("ls"))
+		if expected, actual := true, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v, err: %v", expected, actual, err)
+		}
+
+		c, ok := group.Get("config ls")
+		if expected, actual := true, ok; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := cmd, c; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("resolve", func(t *testing.T) {
+		group := New()
+		group.Add("config list", nil, OptionAliases("ls", "l"))
+
+		if expected, actual := "config list", group.Resolve("config ls"); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := "config list", group.Resolve("config list"); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := true, group.IsAlias("config l"); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := false, group.IsAlias("config list"); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := []string{"config l", "config ls"}, group.Aliases("config list"); !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("resolve nested under alias", func(t *testing.T) {
+		group := New()
+		group.Add("config", nil, OptionAliases("cfg"))
+		group.Add("config show", nil)
+		group.Add("config list", nil, OptionAliases("ls"))
+
+		if expected, actual := "config show", group.Resolve("cfg show"); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := "config list", group.Resolve("cfg ls"); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := "config show foo", group.Resolve("cfg show foo"); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("invalid alias", func(t *testing.T) {
+		group := New()
+		err := group.Add("config list", nil, OptionAliases("a b"))
+		if expected, actual := `alias "a b" must be a single name`, err.Error(); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("duplicate alias", func(t *testing.T) {
+		group := New()
+		group.Add("config ls", nil)
+		err := group.Add("config list", nil, OptionAliases("ls"))
+		if expected, actual := `alias "config ls" already exists`, err.Error(); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("key is an alias", func(t *testing.T) {
+		group := New()
+		group.Add("config list", nil, OptionAliases("ls"))
+		err := group.Add("config ls", nil)
+		if expected, actual := `key "config ls" is already an alias of "config list"`, err.Error(); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := "config list", group.Resolve("config ls"); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("duplicate alias leaves the key", func(t *testing.T) {
+		group := New()
+		group.Add("config ls", nil)
+		group.Add("config list", nil, OptionAliases("ls"))
+		if _, ok := group.Get("config list"); ok {
+			t.Errorf("expected config list to not be added")
+		}
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		group := New()
+		group.Add("config list", nil, OptionAliases("ls"))
+
+		if _, err := group.Remove("config list"); err != nil {
+			t.Error(err)
+		}
+
+		_, ok := group.Get("config ls")
+		if expected, actual := false, ok; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := []string(nil), group.Aliases("config list"); !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+}
+
+func TestHidden(t *testing.T) {
+	t.Parallel()
+
+	t.Run("hidden", func(t *testing.T) {
+		group := New()
+		group.Add("config list", nil, OptionAliases("ls"), OptionHidden())
+		group.Add("config show", nil)
+
+		if expected, actual := true, group.Hidden("config list"); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := true, group.Hidden("config ls"); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := false, group.Hidden("config show"); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("closest name", func(t *testing.T) {
+		group := New()
+		group.Add("config list", nil, OptionHidden())
+
+		_, ok := group.GetClosestName("config lis")
+		if expected, actual := false, ok; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+}
+
+func TestDeprecated(t *testing.T) {
+	t.Parallel()
+
+	t.Run("deprecated", func(t *testing.T) {
+		group := New()
+		group.Add("config ls", nil, OptionDeprecated(" config  list "))
+		group.Add("config list", nil)
+
+		replacement, ok := group.Deprecated("config ls")
+		if expected, actual := true, ok; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := "config list", replacement; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+
+		_, ok = group.Deprecated("config list")
+		if expected, actual := false, ok; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+}
+
 func TestWalkPrefix(t *testing.T) {
 	t.Parallel()
 
@@ -290,6 +463,40 @@ func TestProcess(t *testing.T) {
 	})
 }
 
+func TestProcessInheritsFlags(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	parentFlags := flagset.New("config", flag.ContinueOnError)
+	parentFlags.Persistent().String("server", "", "server address")
+	childFlags := flagset.New("config show", flag.ContinueOnError)
+	nestedFlags := flagset.New("config show else", flag.ContinueOnError)
+
+	parent := NewMockCommand(ctrl)
+	parent.EXPECT().FlagSet().Return(parentFlags).AnyTimes()
+	child := NewMockCommand(ctrl)
+	child.EXPECT().FlagSet().Return(childFlags).AnyTimes()
+	nested := NewMockCommand(ctrl)
+	nested.EXPECT().FlagSet().Return(nestedFlags).AnyTimes()
+
+	group := New()
+	group.Add("config", parent)
+	group.Add("config show", child)
+	group.Add("config show else", nested)
+
+	if err := group.Process(); err != nil {
+		t.Fatal(err)
+	}
+	if expected, actual := true, childFlags.Inherited("server"); expected != actual {
+		t.Errorf("expected: %v, actual: %v", expected, actual)
+	}
+	if expected, actual := true, nestedFlags.Inherited("server"); expected != actual {
+		t.Errorf("expected: %v, actual: %v", expected, actual)
+	}
+}
+
 func TestNormalizeKey(t *testing.T) {
 	t.Parallel()
 
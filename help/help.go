@@ -16,6 +16,21 @@ type Command interface {
 	Synopsis() string
 }
 
+// GlobalFlag describes a flag that is available to every command.
+type GlobalFlag struct {
+	Name  string
+	Short string
+	Usage string
+}
+
+// DefaultGlobalFlags are the global flags that are shown when no other global
+// flags are supplied.
+var DefaultGlobalFlags = []GlobalFlag{
+	{Name: "debug", Usage: "Show all debug messages"},
+	{Name: "help", Short: "h", Usage: "Print command help"},
+	{Name: "version", Short: "v", Usage: "Print client version"},
+}
+
 // HelpOptions represents a way to set optional values to a autocomplete
 // option.
 // The HelpOptions shows what options are available to change.
@@ -24,9 +39,15 @@ type HelpOptions interface {
 	SetHint(string)
 	SetHelp(string)
 	SetErr(string)
+	SetCauses([]string)
+	SetStack(string)
 	SetCommands(map[string]Command)
 	SetFlags([]string)
+	SetInheritedFlags([]string)
+	SetGlobalFlags([]GlobalFlag)
 	SetUsages([]string)
+	SetArgs(string)
+	SetAliases([]string)
 	SetFormat(string)
 	SetColor(bool)
 	SetTemplate(string)
@@ -38,17 +59,23 @@ type HelpOption func(HelpOptions)
 
 // HelpOptions defines options for overriding help rendering.
 type help struct {
-	header   string
-	hint     string
-	help     string
-	err      string
-	commands map[string]Command
-	flags    []string
-	usages   []string
-	format   string
-	color    bool
-	showHelp bool
-	template string
+	header    string
+	hint      string
+	help      string
+	err       string
+	causes    []string
+	stack     string
+	commands  map[string]Command
+	flags     []string
+	inherited []string
+	globals   []GlobalFlag
+	usages    []string
+	args      string
+	aliases   []string
+	format    string
+	color     bool
+	showHelp  bool
+	template  string
 }
 
 func (s *help) SetHeader(p string) {
@@ -67,6 +94,14 @@ func (s *help) SetErr(p string) {
 	s.err = p
 }
 
+func (s *help) SetCauses(p []string) {
+	s.causes = p
+}
+
+func (s *help) SetStack(p string) {
+	s.stack = p
+}
+
 func (s *help) SetCommands(p map[string]Command) {
 	s.commands = p
 }
@@ -75,10 +110,26 @@ func (s *help) SetFlags(p []string) {
 	s.flags = p
 }
 
+func (s *help) SetInheritedFlags(p []string) {
+	s.inherited = p
+}
+
+func (s *help) SetGlobalFlags(p []GlobalFlag) {
+	s.globals = p
+}
+
 func (s *help) SetUsages(p []string) {
 	s.usages = p
 }
 
+func (s *help) SetArgs(p string) {
+	s.args = p
+}
+
+func (s *help) SetAliases(p []string) {
+	s.aliases = p
+}
+
 func (s *help) SetFormat(p string) {
 	s.format = p
 }
@@ -127,6 +178,22 @@ func OptionErr(i string) HelpOption {
 	}
 }
 
+// OptionCauses allows the setting a causes option to configure
+// the group.
+func OptionCauses(i []string) HelpOption {
+	return func(opt HelpOptions) {
+		opt.SetCauses(i)
+	}
+}
+
+// OptionStack allows the setting a stack trace option to configure
+// the group.
+func OptionStack(i string) HelpOption {
+	return func(opt HelpOptions) {
+		opt.SetStack(i)
+	}
+}
+
 // OptionCommands allows the setting a commands option to configure
 // the group.
 func OptionCommands(i map[string]Command) HelpOption {
@@ -143,6 +210,22 @@ func OptionFlags(i []string) HelpOption {
 	}
 }
 
+// OptionInheritedFlags allows the setting a inherited flags option to
+// configure the group.
+func OptionInheritedFlags(i []string) HelpOption {
+	return func(opt HelpOptions) {
+		opt.SetInheritedFlags(i)
+	}
+}
+
+// OptionGlobalFlags allows the setting a global flags option to configure
+// the group.
+func OptionGlobalFlags(i []GlobalFlag) HelpOption {
+	return func(opt HelpOptions) {
+		opt.SetGlobalFlags(i)
+	}
+}
+
 // OptionUsages allows the setting a commands option to configure
 // the group.
 func OptionUsages(i []string) HelpOption {
@@ -151,6 +234,22 @@ func OptionUsages(i []string) HelpOption {
 	}
 }
 
+// OptionArgs allows the setting of the positional arguments, which are shown
+// on the usage line of the command.
+func OptionArgs(i string) HelpOption {
+	return func(opt HelpOptions) {
+		opt.SetArgs(i)
+	}
+}
+
+// OptionAliases allows the setting a aliases option to configure
+// the group.
+func OptionAliases(i []string) HelpOption {
+	return func(opt HelpOptions) {
+		opt.SetAliases(i)
+	}
+}
+
 // OptionFormat allows the setting a format option to configure
 // the group.
 func OptionFormat(i string) HelpOption {
@@ -219,6 +318,11 @@ func BasicFunc(name string) Func {
 			return serialized[i].Name < serialized[j].Name
 		})
 
+		globals := opt.globals
+		if globals == nil {
+			globals = DefaultGlobalFlags
+		}
+
 		format := opt.format
 		if strings.TrimSpace(format) == "" {
 			format = HelpTemplateFormat
@@ -227,32 +331,48 @@ func BasicFunc(name string) Func {
 		if strings.TrimSpace(template) == "" {
 			template = BasicHelpTemplate
 		}
-		formatted := fmt.Sprintf(template, format)
+		// The format is defined as the "command" template, so neither the
+		// template nor the values it renders are ever passed through fmt.
+		formatted := legacyTemplate(template) + `{{define "command"}}` + format + `{{end}}`
 
 		t := ui.NewTemplate(formatted,
 			ui.OptionName("basic-help:"+name),
 			ui.OptionColor(opt.color),
 		)
 		if err := t.Write(writer, struct {
-			Name     string
-			Header   string
-			Hint     string
-			Help     string
-			Err      string
-			Commands []nameHelp
-			Flags    []string
-			Usages   []string
-			ShowHelp bool
+			Name           string
+			Header         string
+			Hint           string
+			Help           string
+			Err            string
+			Causes         []string
+			Stack          string
+			Commands       []nameHelp
+			Flags          []string
+			InheritedFlags []string
+			GlobalFlags    []string
+			GlobalUsage    string
+			Usages         []string
+			Args           string
+			Aliases        []string
+			ShowHelp       bool
 		}{
-			Name:     name,
-			Header:   opt.header,
-			Hint:     opt.hint,
-			Help:     opt.help,
-			Err:      opt.err,
-			Commands: serialized,
-			Flags:    opt.flags,
-			Usages:   opt.usages,
-			ShowHelp: opt.showHelp,
+			Name:           name,
+			Header:         opt.header,
+			Hint:           opt.hint,
+			Help:           opt.help,
+			Err:            opt.err,
+			Causes:         opt.causes,
+			Stack:          opt.stack,
+			Commands:       serialized,
+			Flags:          opt.flags,
+			InheritedFlags: opt.inherited,
+			GlobalFlags:    formatGlobalFlags(globals),
+			GlobalUsage:    globalUsage(globals),
+			Usages:         opt.usages,
+			Args:           opt.args,
+			Aliases:        opt.aliases,
+			ShowHelp:       opt.showHelp,
 		}); err != nil {
 			return "", errors.WithStack(err)
 		}
@@ -264,3 +384,77 @@ func BasicFunc(name string) Func {
 		return strings.TrimSpace(buf.String()) + "\n", nil
 	}
 }
+
+// formatGlobalFlags renders each global flag into a line, with the usage of
+// all the flags aligned.
+// legacyTemplate rewrites a template that uses a "%s" placeholder for the
+// format, outside of any actions, to use the "command" template instead.
+// Escaped percent signs are unescaped, as fmt would have done.
+func legacyTemplate(template string) string {
+	var legacy bool
+	mapText(template, func(text string) string {
+		if strings.Contains(strings.Replace(text, "%%", "", -1), "%s") {
+			legacy = true
+		}
+		return text
+	})
+	if !legacy {
+		return template
+	}
+	replacer := strings.NewReplacer("%%", "%", "%s", `{{template "command" .}}`)
+	return mapText(template, replacer.Replace)
+}
+
+// mapText returns the template with the text outside of the actions mapped
+// by the function. The actions are left as is, so any verbs used by printf
+// aren't changed.
+func mapText(template string, fn func(string) string) string {
+	var buf strings.Builder
+	for rest := template; rest != ""; {
+		idx := strings.Index(rest, "{{")
+		if idx == -1 {
+			idx = len(rest)
+		}
+		buf.WriteString(fn(rest[:idx]))
+		rest = rest[idx:]
+
+		end := strings.Index(rest, "}}")
+		if end == -1 {
+			end = len(rest)
+		} else {
+			end += len("}}")
+		}
+		buf.WriteString(rest[:end])
+		rest = rest[end:]
+	}
+	return buf.String()
+}
+
+// globalUsage returns the global flags in the form they're shown in the usage
+// line, such as "[--debug] [--help]".
+func globalUsage(flags []GlobalFlag) string {
+	usages := make([]string, len(flags))
+	for i, f := range flags {
+		usages[i] = fmt.Sprintf("[--%s]", f.Name)
+	}
+	return strings.Join(usages, " ")
+}
+
+func formatGlobalFlags(flags []GlobalFlag) []string {
+	var width int
+	for _, f := range flags {
+		if len(f.Name) > width {
+			width = len(f.Name)
+		}
+	}
+
+	lines := make([]string, len(flags))
+	for k, f := range flags {
+		short := "    "
+		if f.Short != "" {
+			short = fmt.Sprintf("-%s, ", f.Short)
+		}
+		lines[k] = fmt.Sprintf("    %s--%-*s%s", short, width+6, f.Name, f.Usage)
+	}
+	return lines
+}
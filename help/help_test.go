@@ -20,13 +20,13 @@ func TestBasicFunc(t *testing.T) {
 			t.Errorf("expected: %v, actual: %v, err: %v", expected, actual, err)
 		}
 		required := `
-Usage: foo [--version] [--help] [--debug] <command> [<args>]
+Usage: foo [--debug] [--help] [--version] <command> [<args>]
 
 Global Flags:
 
         --debug        Show all debug messages
     -h, --help         Print command help
-        --version      Print client version
+    -v, --version      Print client version
 `[1:]
 		if expected, actual := required, result; expected != actual {
 			t.Errorf("expected: %v, actual: %v", expected, actual)
@@ -56,7 +56,7 @@ Global Flags:
 			t.Errorf("expected: %v, actual: %v, err: %v", expected, actual, err)
 		}
 		required := strings.TrimSpace(`
-Usage: foo [--version] [--help] [--debug] <command> [<args>]
+Usage: foo [--debug] [--help] [--version] <command> [<args>]
 
 Available commands:
 
@@ -67,7 +67,7 @@ Global Flags:
 
         --debug        Show all debug messages
     -h, --help         Print command help
-        --version      Print client version
+    -v, --version      Print client version
 `) + "\n"
 		if expected, actual := required, result; expected != actual {
 			t.Errorf("expected: %v, actual: %v", expected, actual)
@@ -98,7 +98,7 @@ Global Flags:
 			t.Errorf("expected: %v, actual: %v, err: %v", expected, actual, err)
 		}
 		required := strings.TrimSpace(`
-Usage: foo [--version] [--help] [--debug] <command> [<args>]
+Usage: foo [--debug] [--help] [--version] <command> [<args>]
 
 Available commands:
 
@@ -109,13 +109,39 @@ Global Flags:
 
         --debug        Show all debug messages
     -h, --help         Print command help
-        --version      Print client version
+    -v, --version      Print client version
 `) + "\n"
 		if expected, actual := required, result; expected != actual {
 			t.Errorf("expected: %v, actual: %v", expected, actual)
 		}
 	})
 
+	t.Run("legacy template", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		cmdFoo := NewMockCommand(ctrl)
+		cmdFoo.EXPECT().Synopsis().Return("foo command")
+
+		helpFn := BasicFunc("foo")
+		result, err := helpFn(
+			OptionCommands(map[string]Command{
+				"foo": cmdFoo,
+			}),
+			OptionTemplate(`100%% of {{printf "%s" .Name}}:{{range .Commands}}
+%s{{end}}`),
+			OptionFormat("{{.Name}} - {{.Synopsis}}"),
+		)
+
+		if expected, actual := true, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v, err: %v", expected, actual, err)
+		}
+		required := "100% of foo:\nfoo - foo command\n"
+		if expected, actual := required, result; expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+	})
+
 	t.Run("header", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -142,7 +168,7 @@ Global Flags:
 		required := strings.TrimSpace(`
 **HEADER**
 
-Usage: foo [--version] [--help] [--debug] <command> [<args>]
+Usage: foo [--debug] [--help] [--version] <command> [<args>]
 
 Available commands:
 
@@ -153,7 +179,7 @@ Global Flags:
 
         --debug        Show all debug messages
     -h, --help         Print command help
-        --version      Print client version
+    -v, --version      Print client version
 `) + "\n"
 		if expected, actual := required, result; expected != actual {
 			t.Errorf("expected: %v, actual: %v", expected, actual)
@@ -190,7 +216,7 @@ Global Flags:
 Did you mean?
         foo
 
-Usage: foo [--version] [--help] [--debug] <command> [<args>]
+Usage: foo [--debug] [--help] [--version] <command> [<args>]
 
 Available commands:
 
@@ -201,7 +227,7 @@ Global Flags:
 
         --debug        Show all debug messages
     -h, --help         Print command help
-        --version      Print client version
+    -v, --version      Print client version
 `) + "\n"
 		if expected, actual := required, result; expected != actual {
 			t.Errorf("expected: %v, actual: %v", expected, actual)
@@ -245,6 +271,56 @@ Did you mean?
 	})
 }
 
+func TestGlobalFlags(t *testing.T) {
+	t.Parallel()
+
+	t.Run("global flags", func(t *testing.T) {
+		helpFn := BasicFunc("foo")
+		result, err := helpFn(
+			OptionGlobalFlags([]GlobalFlag{
+				{Name: "help", Short: "h", Usage: "Print command help"},
+				{Name: "profile", Usage: "Profile to use"},
+				{Name: "autocomplete-install", Usage: "Install autocomplete"},
+			}),
+			OptionShowHelp(true),
+		)
+
+		if expected, actual := true, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v, err: %v", expected, actual, err)
+		}
+		required := `
+Usage: foo [--help] [--profile] [--autocomplete-install] <command> [<args>]
+
+Global Flags:
+
+    -h, --help                      Print command help
+        --profile                   Profile to use
+        --autocomplete-install      Install autocomplete
+`[1:]
+		if expected, actual := required, result; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("no global flags", func(t *testing.T) {
+		helpFn := BasicFunc("foo")
+		result, err := helpFn(
+			OptionGlobalFlags([]GlobalFlag{}),
+			OptionShowHelp(true),
+		)
+
+		if expected, actual := true, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v, err: %v", expected, actual, err)
+		}
+		required := `
+Usage: foo <command> [<args>]
+`[1:]
+		if expected, actual := required, result; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+}
+
 func TestComandFunc(t *testing.T) {
 	t.Parallel()
 
@@ -277,7 +353,7 @@ Global Flags:
 
         --debug        Show all debug messages
     -h, --help         Print command help
-        --version      Print client version
+    -v, --version      Print client version
 `[1:]
 		if expected, actual := required, result; expected != actual {
 			t.Errorf("expected: %v, actual: %v", expected, actual)
@@ -306,4 +382,153 @@ See foo --help for more information.
 			t.Errorf("expected: %v, actual: %v", expected, actual)
 		}
 	})
+
+	t.Run("error with verbs", func(t *testing.T) {
+		helpFn := BasicFunc("foo")
+		result, err := helpFn(
+			OptionErr("unexpected %s in %d"),
+			OptionHint("bar"),
+			OptionTemplate(CommandHelpTemplate),
+			OptionShowHelp(false),
+		)
+
+		if expected, actual := true, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v, err: %v", expected, actual, err)
+		}
+		required := `
+Found some issues:
+
+    unexpected %s in %d
+
+See foo --help for more information.
+
+Did you mean?
+    bar
+`[1:]
+		if expected, actual := required, result; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("aliases", func(t *testing.T) {
+		helpFn := BasicFunc("foo bar")
+		result, err := helpFn(
+			OptionAliases([]string{"foo b"}),
+			OptionTemplate(CommandHelpTemplate),
+			OptionShowHelp(true),
+		)
+
+		if expected, actual := true, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v, err: %v", expected, actual, err)
+		}
+		required := `
+Usage:
+
+    foo bar
+
+Aliases:
+
+    foo b
+
+Description:
+    
+
+Global Flags:
+
+        --debug        Show all debug messages
+    -h, --help         Print command help
+    -v, --version      Print client version
+`[1:]
+		if expected, actual := required, result; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("inherited flags", func(t *testing.T) {
+		helpFn := BasicFunc("foo bar")
+		result, err := helpFn(
+			OptionFlags([]string{"--template\tTemplate"}),
+			OptionInheritedFlags([]string{"--server\tServer address"}),
+			OptionTemplate(CommandHelpTemplate),
+			OptionShowHelp(true),
+		)
+
+		if expected, actual := true, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v, err: %v", expected, actual, err)
+		}
+		required := `
+Usage:
+
+    foo bar [flags]
+
+    foo bar --template    Template
+
+Inherited Flags:
+
+    --server            Server address
+
+Description:
+    
+
+Global Flags:
+
+        --debug        Show all debug messages
+    -h, --help         Print command help
+    -v, --version      Print client version
+`[1:]
+		if expected, actual := required, result; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+}
+
+func TestErrorFunc(t *testing.T) {
+	t.Parallel()
+
+	t.Run("error", func(t *testing.T) {
+		helpFn := BasicFunc("foo")
+		result, err := helpFn(
+			OptionErr("something went wrong"),
+			OptionTemplate(ErrorTemplate),
+		)
+
+		if expected, actual := true, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v, err: %v", expected, actual, err)
+		}
+		required := `
+Error: something went wrong
+`[1:]
+		if expected, actual := required, result; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("error with causes", func(t *testing.T) {
+		helpFn := BasicFunc("foo")
+		result, err := helpFn(
+			OptionErr("request: timeout"),
+			OptionCauses([]string{"timeout"}),
+			OptionStack("main.main\n    /main.go:1"),
+			OptionTemplate(ErrorTemplate),
+		)
+
+		if expected, actual := true, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v, err: %v", expected, actual, err)
+		}
+		required := `
+Error: request: timeout
+
+Caused by:
+
+    timeout
+
+Stack trace:
+    main.main
+        /main.go:1
+`[1:]
+		if expected, actual := required, result; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
 }
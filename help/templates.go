@@ -1,6 +1,7 @@
 package help
 
 // HelpTemplateFormat defines a basic format for templating a help template.
+// It's rendered for each command by the "command" template.
 const HelpTemplateFormat = `    {{green .Name}}	{{.Synopsis}}`
 
 // BasicHelpTemplate is the current view template of the help output.
@@ -13,21 +14,23 @@ Did you mean?
         {{green .Hint}}
 {{end}}
 {{- if .ShowHelp }}
-Usage: {{green .Name}} [--version] [--help] [--debug] <command> [<args>]
+Usage: {{green .Name}}{{if .GlobalUsage}} {{.GlobalUsage}}{{end}} <command> [<args>]
 
 {{- if gt (len .Commands) 0 }}
 
 Available commands:
 {{ range .Commands }}
-%s
+{{template "command" .}}
 {{- end}}
 {{- end}}
 
-Global Flags:
+{{- if gt (len .GlobalFlags) 0 }}
 
-        --debug        Show all debug messages
-    -h, --help         Print command help
-        --version      Print client version
+Global Flags:
+{{ range $flag := .GlobalFlags }}
+{{$flag}}
+{{- end}}
+{{- end}}
 {{- end}}
 `
 
@@ -50,7 +53,7 @@ Did you mean?
 {{- if .Name}}
 Usage:
 
-    {{green .Name}}{{- if .Flags}} [flags]{{- end}}
+    {{green .Name}}{{- if .Flags}} [flags]{{- end}}{{- if .Args}} {{.Args}}{{- end}}
 
 {{- if gt (len .Flags) 0 }}
 {{range $flag := .Flags }}
@@ -63,6 +66,20 @@ Usage:
 {{- end}}
 {{- end}}
 {{- end}}
+{{- if gt (len .InheritedFlags) 0 }}
+
+Inherited Flags:
+{{range $flag := .InheritedFlags }}
+    {{$flag}}
+{{- end}}
+{{- end}}
+{{- if gt (len .Aliases) 0 }}
+
+Aliases:
+{{range $alias := .Aliases }}
+    {{green $alias}}
+{{- end}}
+{{- end}}
 
 Description:
     {{ indent .Help }}
@@ -75,10 +92,30 @@ Available commands:
 {{- end}}
 {{- end}}
 
+{{- if gt (len .GlobalFlags) 0 }}
+
 Global Flags:
+{{ range $flag := .GlobalFlags }}
+{{$flag}}
+{{- end}}
+{{- end}}
+{{- end}}
+`
+
+// ErrorTemplate represents a template for rendering runtime errors from
+// commands, that aren't related to how the command was used.
+const ErrorTemplate = `
+Error: {{red .Err}}
+{{- if gt (len .Causes) 0 }}
+
+Caused by:
+{{range $cause := .Causes }}
+    {{$cause}}
+{{- end}}
+{{- end}}
+{{- if .Stack }}
 
-        --debug        Show all debug messages
-    -h, --help         Print command help
-        --version      Print client version
+Stack trace:
+{{ indent .Stack }}
 {{- end}}
 `
@@ -0,0 +1,427 @@
+package man
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spoke-d/clui/flagset"
+	"github.com/spoke-d/clui/group"
+	"github.com/spoke-d/clui/help"
+	"github.com/spoke-d/clui/radix"
+)
+
+// Page is a man page for a command, or the top level page for the CLI.
+type Page struct {
+	// Name of the page, such as "mycli-config-show".
+	Name string
+
+	// Section of the manual the page belongs to.
+	Section int
+
+	// Content of the page, as roff.
+	Content []byte
+}
+
+// Filename returns the file name of the page, such as "mycli-config-show.1".
+func (p Page) Filename() string {
+	return fmt.Sprintf("%s.%d", p.Name, p.Section)
+}
+
+// ManOptions represents a way to set optional values to a man option.
+// The ManOptions shows what options are available to change.
+type ManOptions interface {
+	SetSection(int)
+	SetDate(time.Time)
+	SetVersion(string)
+	SetManual(string)
+	SetDescription(string)
+	SetGlobalFlags([]help.GlobalFlag)
+}
+
+// ManOption captures a tweak that can be applied to the generated pages.
+type ManOption func(ManOptions)
+
+type man struct {
+	section     int
+	date        time.Time
+	version     string
+	manual      string
+	description string
+	globalFlags []help.GlobalFlag
+}
+
+func (s *man) SetSection(p int) {
+	s.section = p
+}
+
+func (s *man) SetDate(p time.Time) {
+	s.date = p
+}
+
+func (s *man) SetVersion(p string) {
+	s.version = p
+}
+
+func (s *man) SetManual(p string) {
+	s.manual = p
+}
+
+func (s *man) SetDescription(p string) {
+	s.description = p
+}
+
+func (s *man) SetGlobalFlags(p []help.GlobalFlag) {
+	s.globalFlags = p
+}
+
+// OptionSection allows the setting a section option to configure the pages.
+// The section is 1 (user commands) by default.
+func OptionSection(i int) ManOption {
+	return func(opt ManOptions) {
+		opt.SetSection(i)
+	}
+}
+
+// OptionDate allows the setting a date option to configure the pages. The
+// pages have no date by default, so that they're the same every time they're
+// generated.
+func OptionDate(i time.Time) ManOption {
+	return func(opt ManOptions) {
+		opt.SetDate(i)
+	}
+}
+
+// OptionVersion allows the setting a version option to configure the pages.
+func OptionVersion(i string) ManOption {
+	return func(opt ManOptions) {
+		opt.SetVersion(i)
+	}
+}
+
+// OptionManual allows the setting a manual option to configure the title of
+// the manual the pages belong to.
+func OptionManual(i string) ManOption {
+	return func(opt ManOptions) {
+		opt.SetManual(i)
+	}
+}
+
+// OptionDescription allows the setting a description option to configure the
+// description on the top level page.
+func OptionDescription(i string) ManOption {
+	return func(opt ManOptions) {
+		opt.SetDescription(i)
+	}
+}
+
+// OptionGlobalFlags allows the setting of the global flags that are
+// documented on the top level page.
+func OptionGlobalFlags(i []help.GlobalFlag) ManOption {
+	return func(opt ManOptions) {
+		opt.SetGlobalFlags(i)
+	}
+}
+
+// Generate walks the commands and creates a page for every command, along
+// with a top level page for the CLI. Hidden commands and aliases are skipped.
+// The group should be processed before generating the pages, so that every
+// nested command has a parent.
+func Generate(name string, commands *group.Group, options ...ManOption) ([]Page, error) {
+	opt := &man{
+		section: 1,
+		manual:  fmt.Sprintf("%s Manual", name),
+	}
+	for _, option := range options {
+		option(opt)
+	}
+
+	var keys []string
+	commands.WalkPrefix("", func(key string, _ radix.Value) bool {
+		if !commands.Hidden(key) && !commands.IsAlias(key) {
+			keys = append(keys, key)
+		}
+		return false
+	})
+	sort.Strings(keys)
+
+	g := &generator{
+		name:     name,
+		opt:      opt,
+		commands: commands,
+		keys:     keys,
+	}
+
+	pages := []Page{g.root()}
+	for _, key := range keys {
+		cmd, ok := commands.Get(key)
+		if !ok {
+			return nil, errors.Errorf("command %q not found", key)
+		}
+		pages = append(pages, g.command(key, cmd))
+	}
+	return pages, nil
+}
+
+// Write writes the pages to the directory, creating the directory if it
+// doesn't exist.
+func Write(dir string, pages []Page) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return errors.WithStack(err)
+	}
+	for _, page := range pages {
+		path := filepath.Join(dir, page.Filename())
+		if err := ioutil.WriteFile(path, page.Content, 0644); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+type generator struct {
+	name     string
+	opt      *man
+	commands *group.Group
+	keys     []string
+}
+
+func (g *generator) root() Page {
+	var buf bytes.Buffer
+	g.header(&buf, g.name)
+
+	fmt.Fprintf(&buf, ".SH NAME\n%s", escape(g.name))
+	if g.opt.description != "" {
+		fmt.Fprintf(&buf, " \\- %s", escape(firstLine(g.opt.description)))
+	}
+	fmt.Fprintln(&buf)
+
+	fmt.Fprintf(&buf, ".SH SYNOPSIS\n.B %s\n[global flags] <command> [<args>]\n", escape(g.name))
+
+	if g.opt.description != "" {
+		fmt.Fprintln(&buf, ".SH DESCRIPTION")
+		paragraphs(&buf, g.opt.description)
+	}
+
+	g.globalFlags(&buf)
+	g.children(&buf, "")
+	g.seeAlso(&buf, "")
+
+	return g.page(g.name, buf.Bytes())
+}
+
+func (g *generator) command(key string, cmd group.Command) Page {
+	var (
+		buf  bytes.Buffer
+		name = g.pageName(key)
+		full = fmt.Sprintf("%s %s", g.name, key)
+	)
+	g.header(&buf, name)
+
+	fmt.Fprintf(&buf, ".SH NAME\n%s", escape(name))
+	if synopsis := cmd.Synopsis(); synopsis != "" {
+		fmt.Fprintf(&buf, " \\- %s", escape(synopsis))
+	}
+	fmt.Fprintln(&buf)
+
+	var own, inherited []*flag.Flag
+	flagSet := cmd.FlagSet()
+	flagSet.VisitAll(func(f *flag.Flag) {
+		if flagSet.Inherited(f.Name) {
+			inherited = append(inherited, f)
+		} else {
+			own = append(own, f)
+		}
+	})
+
+	fmt.Fprintf(&buf, ".SH SYNOPSIS\n.B %s\n", escape(full))
+	if len(own)+len(inherited) > 0 {
+		fmt.Fprintln(&buf, "[flags]")
+	}
+	for _, usage := range cmd.Usages() {
+		fmt.Fprintf(&buf, ".br\n.B %s\n%s\n", escape(full), escape(usage))
+	}
+
+	if text := strings.TrimSpace(cmd.Help()); text != "" {
+		fmt.Fprintln(&buf, ".SH DESCRIPTION")
+		paragraphs(&buf, text)
+	}
+
+	flags(&buf, "OPTIONS", flagSet, own)
+	flags(&buf, "INHERITED OPTIONS", flagSet, inherited)
+
+	if aliases := g.commands.Aliases(key); len(aliases) > 0 {
+		fmt.Fprintln(&buf, ".SH ALIASES")
+		for i, alias := range aliases {
+			if i > 0 {
+				fmt.Fprintln(&buf, ".br")
+			}
+			fmt.Fprintf(&buf, "%s %s\n", escape(g.name), escape(alias))
+		}
+	}
+
+	if replacement, ok := g.commands.Deprecated(key); ok {
+		fmt.Fprintf(&buf, ".SH DEPRECATED\nUse \\fB%s %s\\fP instead.\n", escape(g.name), escape(replacement))
+	}
+
+	g.children(&buf, key)
+	g.seeAlso(&buf, key)
+
+	return g.page(name, buf.Bytes())
+}
+
+func (g *generator) header(buf *bytes.Buffer, name string) {
+	source := g.name
+	if g.opt.version != "" {
+		source = fmt.Sprintf("%s %s", g.name, g.opt.version)
+	}
+	var date string
+	if !g.opt.date.IsZero() {
+		date = g.opt.date.Format("Jan 2006")
+	}
+	fmt.Fprintf(buf, ".TH %q %q %q %q %q\n",
+		strings.ToUpper(name),
+		fmt.Sprint(g.opt.section),
+		date,
+		source,
+		g.opt.manual,
+	)
+}
+
+func (g *generator) globalFlags(buf *bytes.Buffer) {
+	if len(g.opt.globalFlags) == 0 {
+		return
+	}
+	fmt.Fprintln(buf, ".SH GLOBAL OPTIONS")
+	for _, f := range g.opt.globalFlags {
+		fmt.Fprintln(buf, ".TP")
+		if f.Short != "" {
+			fmt.Fprintf(buf, "\\fB\\-%s\\fP, ", escape(f.Short))
+		}
+		fmt.Fprintf(buf, "\\fB\\-\\-%s\\fP\n%s\n", escape(f.Name), escape(f.Usage))
+	}
+}
+
+// children lists the commands directly under the key.
+func (g *generator) children(buf *bytes.Buffer, key string) {
+	children := g.childKeys(key)
+	if len(children) == 0 {
+		return
+	}
+	fmt.Fprintln(buf, ".SH COMMANDS")
+	for _, child := range children {
+		cmd, ok := g.commands.Get(child)
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(buf, ".TP\n\\fB%s\\fP\n%s\n", escape(child), escape(cmd.Synopsis()))
+	}
+}
+
+// seeAlso references the parent page and the pages of the children.
+func (g *generator) seeAlso(buf *bytes.Buffer, key string) {
+	var refs []string
+	if key != "" {
+		parent := g.name
+		if idx := strings.LastIndex(key, " "); idx >= 0 {
+			parent = g.pageName(key[:idx])
+		}
+		refs = append(refs, parent)
+	}
+	for _, child := range g.childKeys(key) {
+		refs = append(refs, g.pageName(child))
+	}
+	if len(refs) == 0 {
+		return
+	}
+
+	fmt.Fprintln(buf, ".SH SEE ALSO")
+	for i, ref := range refs {
+		if i > 0 {
+			fmt.Fprint(buf, ", ")
+		}
+		fmt.Fprintf(buf, "\\fB%s(%d)\\fP", escape(ref), g.opt.section)
+	}
+	fmt.Fprintln(buf)
+}
+
+func (g *generator) childKeys(key string) []string {
+	var children []string
+	for _, k := range g.keys {
+		if key == "" && !strings.Contains(k, " ") {
+			children = append(children, k)
+		} else if key != "" && strings.HasPrefix(k, key+" ") && !strings.Contains(k[len(key)+1:], " ") {
+			children = append(children, k)
+		}
+	}
+	return children
+}
+
+func (g *generator) pageName(key string) string {
+	return strings.Join(append([]string{g.name}, strings.Fields(key)...), "-")
+}
+
+func (g *generator) page(name string, content []byte) Page {
+	return Page{
+		Name:    name,
+		Section: g.opt.section,
+		Content: content,
+	}
+}
+
+func flags(buf *bytes.Buffer, title string, flagSet *flagset.FlagSet, flags []*flag.Flag) {
+	if len(flags) == 0 {
+		return
+	}
+	fmt.Fprintf(buf, ".SH %s\n", title)
+	for _, f := range flags {
+		_, usage := flag.UnquoteUsage(f)
+		typ := flagSet.Type(f.Name)
+		fmt.Fprint(buf, ".TP\n")
+		if short := flagSet.Short(f.Name); short != "" {
+			fmt.Fprintf(buf, "\\fB\\-%s\\fP, ", escape(short))
+		}
+		fmt.Fprintf(buf, "\\fB\\-\\-%s\\fP", escape(f.Name))
+		if typ != "" {
+			fmt.Fprintf(buf, " \\fI%s\\fP", escape(typ))
+		}
+		fmt.Fprintln(buf)
+		fmt.Fprint(buf, escape(usage))
+		if f.DefValue != "" && f.DefValue != "false" && f.DefValue != "0" {
+			fmt.Fprintf(buf, " (default: %s)", escape(f.DefValue))
+		}
+		fmt.Fprintln(buf)
+	}
+}
+
+// paragraphs writes the text, splitting it into paragraphs on blank lines.
+func paragraphs(buf *bytes.Buffer, text string) {
+	for i, paragraph := range strings.Split(strings.TrimSpace(text), "\n\n") {
+		if i > 0 {
+			fmt.Fprintln(buf, ".PP")
+		}
+		for _, line := range strings.Split(strings.TrimSpace(paragraph), "\n") {
+			fmt.Fprintln(buf, escape(strings.TrimSpace(line)))
+		}
+	}
+}
+
+// escape escapes the text, so that it is rendered as is by roff.
+func escape(text string) string {
+	text = strings.Replace(text, `\`, `\e`, -1)
+	text = strings.Replace(text, "-", `\-`, -1)
+	if strings.HasPrefix(text, ".") || strings.HasPrefix(text, "'") {
+		text = `\&` + text
+	}
+	return text
+}
+
+func firstLine(text string) string {
+	return strings.TrimSpace(strings.SplitN(strings.TrimSpace(text), "\n", 2)[0])
+}
@@ -0,0 +1,209 @@
+package man
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spoke-d/clui/commands"
+	"github.com/spoke-d/clui/flagset"
+	"github.com/spoke-d/clui/group"
+	"github.com/spoke-d/clui/help"
+	task "github.com/spoke-d/task/group"
+)
+
+type stubCommand struct {
+	flagSet  *flagset.FlagSet
+	usages   []string
+	help     string
+	synopsis string
+}
+
+func newStubCommand(synopsis, help string, usages ...string) *stubCommand {
+	return &stubCommand{
+		flagSet:  flagset.New(synopsis, flag.ContinueOnError),
+		usages:   usages,
+		help:     help,
+		synopsis: synopsis,
+	}
+}
+
+func (c *stubCommand) FlagSet() *flagset.FlagSet                    { return c.flagSet }
+func (c *stubCommand) Usages() []string                             { return c.usages }
+func (c *stubCommand) Help() string                                 { return c.help }
+func (c *stubCommand) Synopsis() string                             { return c.synopsis }
+func (c *stubCommand) Init([]string, commands.CommandContext) error { return nil }
+func (c *stubCommand) Run(g *task.Group)                            { commands.Nothing(g) }
+
+func newGroup(t *testing.T) *group.Group {
+	config := newStubCommand("Manage configuration.", "Manage the configuration.")
+	config.FlagSet().Persistent().String("server", "localhost", "Server to `address`")
+
+	show := newStubCommand("Show configuration.", `
+Show the current configuration.
+
+Use --server to pick another server.`, "<key>")
+	show.FlagSet().Bool("all", false, "Show all keys")
+
+	g := group.New()
+	for _, c := range []struct {
+		key     string
+		cmd     group.Command
+		options []group.CommandOption
+	}{
+		{key: "config", cmd: config},
+		{key: "config show", cmd: show, options: []group.CommandOption{group.OptionAliases("get")}},
+		{key: "secret", cmd: newStubCommand("Secret.", ""), options: []group.CommandOption{group.OptionHidden()}},
+	} {
+		if err := g.Add(c.key, c.cmd, c.options...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := g.Process(); err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2020, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("pages", func(t *testing.T) {
+		pages, err := Generate("cli", newGroup(t), OptionDate(date))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var names []string
+		for _, page := range pages {
+			names = append(names, page.Filename())
+		}
+		if expected, actual := []string{"cli.1", "cli-config.1", "cli-config-show.1"}, names; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("root", func(t *testing.T) {
+		pages, err := Generate("cli", newGroup(t),
+			OptionDate(date),
+			OptionVersion("1.0.0"),
+			OptionDescription("A command line interface."),
+			OptionGlobalFlags([]help.GlobalFlag{
+				{Name: "help", Short: "h", Usage: "Print command help"},
+			}),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := `.TH "CLI" "1" "Jun 2020" "cli 1.0.0" "cli Manual"
+.SH NAME
+cli \- A command line interface.
+.SH SYNOPSIS
+.B cli
+[global flags] <command> [<args>]
+.SH DESCRIPTION
+A command line interface.
+.SH GLOBAL OPTIONS
+.TP
+\fB\-h\fP, \fB\-\-help\fP
+Print command help
+.SH COMMANDS
+.TP
+\fBconfig\fP
+Manage configuration.
+.SH SEE ALSO
+\fBcli\-config(1)\fP
+`
+		if actual := string(pages[0].Content); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("command", func(t *testing.T) {
+		pages, err := Generate("cli", newGroup(t), OptionDate(date))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := `.TH "CLI-CONFIG-SHOW" "1" "Jun 2020" "cli" "cli Manual"
+.SH NAME
+cli\-config\-show \- Show configuration.
+.SH SYNOPSIS
+.B cli config show
+[flags]
+.br
+.B cli config show
+<key>
+.SH DESCRIPTION
+Show the current configuration.
+.PP
+Use \-\-server to pick another server.
+.SH OPTIONS
+.TP
+\fB\-\-all\fP
+Show all keys
+.SH INHERITED OPTIONS
+.TP
+\fB\-\-server\fP \fIaddress\fP
+Server to address (default: localhost)
+.SH ALIASES
+cli config get
+.SH SEE ALSO
+\fBcli\-config(1)\fP
+`
+		if actual := string(pages[2].Content); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("no date", func(t *testing.T) {
+		pages, err := Generate("cli", newGroup(t))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		header := strings.SplitN(string(pages[2].Content), "\n", 2)[0]
+		if expected, actual := `.TH "CLI-CONFIG-SHOW" "1" "" "cli" "cli Manual"`, header; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+}
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "man")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cmd := NewCommand(func() ([]Page, error) {
+		return []Page{{Name: "cli", Section: 1, Content: []byte(".TH CLI\n")}}, nil
+	})
+	if err := cmd.FlagSet().Parse([]string{"--dir", filepath.Join(dir, "man1")}); err != nil {
+		t.Fatal(err)
+	}
+
+	g := task.NewGroup()
+	cmd.Run(g)
+	if err := g.Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, "man1", "cli.1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected, actual := ".TH CLI\n", string(content); expected != actual {
+		t.Errorf("expected: %v, actual: %v", expected, actual)
+	}
+}
@@ -0,0 +1,41 @@
+package clui
+
+import (
+	"github.com/spoke-d/clui/commands"
+	task "github.com/spoke-d/task/group"
+)
+
+// Invocation describes a command that is about to be initialised and run.
+type Invocation struct {
+	// Key is the resolved sub command key, aliases are resolved to the
+	// command they represent.
+	Key string
+
+	// Args are the arguments passed to the command, without any flags.
+	Args []string
+
+	// Context is the context the command is run with.
+	Context commands.CommandContext
+
+	// Command is the command that is being run.
+	Command Command
+}
+
+// Handler initialises and runs a command with the given task group.
+// Returns the exit code along with any error that stopped the command from
+// running.
+type Handler func(Invocation, *task.Group) (Errno, error)
+
+// Middleware wraps a Handler to allow logic to be called around every
+// command. Middleware can stop the command from running by not calling the
+// next Handler and returning an error or an exit code instead.
+type Middleware func(Handler) Handler
+
+// chain wraps the handler with the middleware, with the first middleware
+// being the outer most.
+func chain(handler Handler, middleware ...Middleware) Handler {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+	return handler
+}
@@ -0,0 +1,125 @@
+package clui
+
+import (
+	"bytes"
+	"flag"
+	"reflect"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/spoke-d/clui/autocomplete"
+	"github.com/spoke-d/clui/commands"
+	"github.com/spoke-d/clui/flagset"
+	"github.com/spoke-d/clui/ui"
+	task "github.com/spoke-d/task/group"
+)
+
+func TestChain(t *testing.T) {
+	t.Parallel()
+
+	t.Run("order", func(t *testing.T) {
+		var calls []string
+		middleware := func(name string) Middleware {
+			return func(next Handler) Handler {
+				return func(inv Invocation, g *task.Group) (Errno, error) {
+					calls = append(calls, name)
+					return next(inv, g)
+				}
+			}
+		}
+		handler := chain(func(Invocation, *task.Group) (Errno, error) {
+			calls = append(calls, "handler")
+			return EOK, nil
+		}, middleware("a"), middleware("b"))
+
+		code, err := handler(Invocation{}, task.NewGroup())
+		if expected, actual := true, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v, err: %v", expected, actual, err)
+		}
+		if expected, actual := EOK, code; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := []string{"a", "b", "handler"}, calls; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+}
+
+func TestMiddleware(t *testing.T) {
+	t.Parallel()
+
+	t.Run("invocation", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		cmd := NewMockCommand(ctrl)
+		cmd.EXPECT().FlagSet().Return(flagset.New("foo", flag.ContinueOnError))
+		cmd.EXPECT().Init([]string{"a"}, gomock.Any()).Return(nil)
+		cmd.EXPECT().Run(gomock.Any()).Do(commands.Nothing)
+
+		var inv Invocation
+		var buf bytes.Buffer
+		cli := New("cli", "1.0.0", "",
+			OptionUI(ui.NewBasicUI(nil, &buf, &buf)),
+			OptionAutoCompleter(nopAutoCompleter{}),
+			OptionMiddleware(func(next Handler) Handler {
+				return func(i Invocation, g *task.Group) (Errno, error) {
+					inv = i
+					return next(i, g)
+				}
+			}),
+		)
+		cli.Add("foo bar", func(UI) Command { return cmd }, OptionAliases("baz"))
+
+		code, err := cli.Run([]string{"foo", "baz", "a", "--debug"})
+		if expected, actual := true, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v, err: %v", expected, actual, err)
+		}
+		if expected, actual := EOK, code; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := "foo bar", inv.Key; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := true, inv.Context.Debug; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("stop with exit code", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		cmd := NewMockCommand(ctrl)
+		cmd.EXPECT().FlagSet().Return(flagset.New("foo", flag.ContinueOnError))
+
+		var buf bytes.Buffer
+		cli := New("cli", "1.0.0", "",
+			OptionUI(ui.NewBasicUI(nil, &buf, &buf)),
+			OptionAutoCompleter(nopAutoCompleter{}),
+			OptionMiddleware(func(Handler) Handler {
+				return func(Invocation, *task.Group) (Errno, error) {
+					return Errno(3), nil
+				}
+			}),
+		)
+		cli.Add("foo", func(UI) Command { return cmd })
+
+		code, err := cli.Run([]string{"foo"})
+		if expected, actual := true, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v, err: %v", expected, actual, err)
+		}
+		if expected, actual := Errno(3), code; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := "", buf.String(); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+}
+
+type nopAutoCompleter struct{}
+
+func (nopAutoCompleter) Complete(string) ([]autocomplete.Candidate, bool) { return nil, false }
+func (nopAutoCompleter) Install(string) error                             { return nil }
+func (nopAutoCompleter) Uninstall(string) error                           { return nil }
@@ -0,0 +1,365 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spoke-d/clui/commands"
+	"github.com/spoke-d/clui/flagset"
+	"github.com/spoke-d/task/group"
+)
+
+const (
+	// ManifestExt is the extension of a manifest file that can be placed next
+	// to a plugin executable, to describe the plugin.
+	ManifestExt = ".json"
+
+	// SynopsisFlag is the flag passed to a plugin to probe for the synopsis,
+	// when there is no manifest file.
+	SynopsisFlag = "--synopsis"
+
+	probeTimeout = 2 * time.Second
+)
+
+// Manifest describes a plugin, without having to run the plugin.
+type Manifest struct {
+	Synopsis string `json:"synopsis"`
+	Help     string `json:"help"`
+}
+
+// FinderOptions represents a way to set optional values to a finder option.
+// The FinderOptions shows what options are available to change.
+type FinderOptions interface {
+	SetPath(string)
+	AppendDirs(...string)
+	SetEnv(func(string) (string, bool))
+	SetStdio(io.Reader, io.Writer, io.Writer)
+}
+
+// FinderOption captures a tweak that can be applied to the Finder.
+type FinderOption func(FinderOptions)
+
+type finder struct {
+	path   *string
+	dirs   []string
+	env    func(string) (string, bool)
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func (s *finder) SetPath(p string) {
+	s.path = &p
+}
+
+func (s *finder) AppendDirs(p ...string) {
+	s.dirs = append(s.dirs, p...)
+}
+
+func (s *finder) SetEnv(p func(string) (string, bool)) {
+	s.env = p
+}
+
+func (s *finder) SetStdio(stdin io.Reader, stdout, stderr io.Writer) {
+	s.stdin = stdin
+	s.stdout = stdout
+	s.stderr = stderr
+}
+
+func (s *finder) Dirs() []string {
+	lookupEnv := s.env
+	if lookupEnv == nil {
+		lookupEnv = os.LookupEnv
+	}
+	path, _ := lookupEnv("PATH")
+	if s.path != nil {
+		path = *s.path
+	}
+	return append(filepath.SplitList(path), s.dirs...)
+}
+
+// OptionPath allows the setting a path option to configure the finder. The
+// path is a list of directories in the same form as the PATH environment
+// variable, which is used by default.
+func OptionPath(i string) FinderOption {
+	return func(opt FinderOptions) {
+		opt.SetPath(i)
+	}
+}
+
+// OptionDirs allows the appending of plugin directories to configure the
+// finder. Plugin directories are searched after the path.
+func OptionDirs(i ...string) FinderOption {
+	return func(opt FinderOptions) {
+		opt.AppendDirs(i...)
+	}
+}
+
+// OptionEnv allows the setting of the function used to look up the PATH
+// environment variable, which is os.LookupEnv by default.
+func OptionEnv(i func(string) (string, bool)) FinderOption {
+	return func(opt FinderOptions) {
+		opt.SetEnv(i)
+	}
+}
+
+// OptionStdio allows the setting of the standard input and outputs that the
+// plugins are run with.
+func OptionStdio(stdin io.Reader, stdout, stderr io.Writer) FinderOption {
+	return func(opt FinderOptions) {
+		opt.SetStdio(stdin, stdout, stderr)
+	}
+}
+
+// Finder discovers plugins for a CLI. Plugins are executables named after the
+// CLI and the command they provide, so "mycli-foo-bar" provides the
+// "foo bar" command for "mycli". An underscore in the name is used for a
+// dash in the command, so "mycli-dry_run" provides the "dry-run" command.
+type Finder struct {
+	prefix string
+	dirs   []string
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// NewFinder creates a Finder for the named CLI with sane defaults.
+func NewFinder(name string, options ...FinderOption) *Finder {
+	opt := new(finder)
+	for _, option := range options {
+		option(opt)
+	}
+
+	f := &Finder{
+		prefix: name + "-",
+		dirs:   opt.Dirs(),
+		stdin:  opt.stdin,
+		stdout: opt.stdout,
+		stderr: opt.stderr,
+	}
+	if f.stdin == nil {
+		f.stdin = os.Stdin
+	}
+	if f.stdout == nil {
+		f.stdout = os.Stdout
+	}
+	if f.stderr == nil {
+		f.stderr = os.Stderr
+	}
+	return f
+}
+
+// Find returns all the plugins that can be found, keyed by the command they
+// provide. If the same plugin is found in more than one directory, the first
+// one found is used.
+func (f *Finder) Find() map[string]*Command {
+	plugins := make(map[string]*Command)
+	for _, dir := range f.dirs {
+		if dir == "" {
+			continue
+		}
+		// Directories that can't be read are skipped, as the path can contain
+		// directories that don't exist.
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, file := range files {
+			key, ok := f.key(file)
+			if !ok {
+				continue
+			}
+			if _, ok := plugins[key]; ok {
+				continue
+			}
+			plugins[key] = f.command(filepath.Join(dir, file.Name()))
+		}
+	}
+	return plugins
+}
+
+func (f *Finder) key(file os.FileInfo) (string, bool) {
+	name := file.Name()
+	if !strings.HasPrefix(name, f.prefix) || filepath.Ext(name) == ManifestExt {
+		return "", false
+	}
+	if file.IsDir() || file.Mode()&0111 == 0 {
+		return "", false
+	}
+
+	var names []string
+	for _, v := range strings.Split(strings.TrimPrefix(name, f.prefix), "-") {
+		if v != "" {
+			names = append(names, strings.Replace(v, "_", "-", -1))
+		}
+	}
+	if len(names) == 0 {
+		return "", false
+	}
+	return strings.Join(names, " "), true
+}
+
+func (f *Finder) command(path string) *Command {
+	return &Command{
+		path:    path,
+		flagSet: flagset.New(filepath.Base(path), flag.ContinueOnError),
+		stdin:   f.stdin,
+		stdout:  f.stdout,
+		stderr:  f.stderr,
+	}
+}
+
+// Command is a command that runs a plugin executable. All the arguments,
+// including any flags, are passed to the plugin as is.
+type Command struct {
+	path     string
+	flagSet  *flagset.FlagSet
+	once     sync.Once
+	manifest Manifest
+	args     []string
+
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// Path returns the path of the plugin executable.
+func (c *Command) Path() string {
+	return c.path
+}
+
+// External returns true, as the plugin parses its own flags.
+func (c *Command) External() bool {
+	return true
+}
+
+// FlagSet returns the FlagSet associated with the command. Plugins parse their
+// own flags, so the FlagSet is always empty.
+func (c *Command) FlagSet() *flagset.FlagSet {
+	return c.flagSet
+}
+
+// Usages returns various usages that can be used for the command.
+func (c *Command) Usages() []string {
+	return make([]string, 0)
+}
+
+// Help should return a long-form help text that includes the command-line
+// usage. A brief few sentences explaining the function of the command, and
+// the complete list of flags the command accepts.
+func (c *Command) Help() string {
+	if help := c.describe().Help; help != "" {
+		return help
+	}
+	return fmt.Sprintf("External plugin command found at %q.", c.path)
+}
+
+// Synopsis should return a one-line, short synopsis of the command.
+// This should be short (50 characters of less ideally).
+func (c *Command) Synopsis() string {
+	return c.describe().Synopsis
+}
+
+// Init is called with all the args required to run a command.
+// This is separated from Run, to allow the preperation of a command, before
+// it's run.
+func (c *Command) Init(args []string, ctx commands.CommandContext) error {
+	c.args = args
+	return nil
+}
+
+// Run subscribes to the group for executing the plugin. The exit code of the
+// plugin is returned as an ExitError.
+func (c *Command) Run(g *group.Group) {
+	ctx, cancel := context.WithCancel(context.Background())
+	g.Add(func(context.Context) error {
+		cmd := exec.CommandContext(ctx, c.path, c.args...)
+		cmd.Stdin = c.stdin
+		cmd.Stdout = c.stdout
+		cmd.Stderr = c.stderr
+
+		err := cmd.Run()
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return &ExitError{
+				name: filepath.Base(c.path),
+				code: exitErr.ExitCode(),
+			}
+		}
+		return errors.WithStack(err)
+	}, func(error) {
+		cancel()
+	})
+}
+
+// Describe describes the plugins in parallel, so that listing a lot of plugins
+// that need to be probed for their synopsis doesn't take the probe timeout of
+// each one in turn. The descriptions are cached by the commands.
+func Describe(commands ...*Command) {
+	var wg sync.WaitGroup
+	for _, cmd := range commands {
+		wg.Add(1)
+		go func(cmd *Command) {
+			defer wg.Done()
+			cmd.describe()
+		}(cmd)
+	}
+	wg.Wait()
+}
+
+// describe returns the manifest of the plugin, either from the manifest file
+// or by probing the plugin for the synopsis. The plugin is only described
+// once.
+func (c *Command) describe() Manifest {
+	c.once.Do(func() {
+		c.manifest = c.readManifest()
+	})
+	return c.manifest
+}
+
+func (c *Command) readManifest() Manifest {
+	var manifest Manifest
+	if file, err := ioutil.ReadFile(c.path + ManifestExt); err == nil {
+		if err := json.Unmarshal(file, &manifest); err == nil {
+			return manifest
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, c.path, SynopsisFlag).Output()
+	if err != nil {
+		return Manifest{}
+	}
+	if lines := strings.SplitN(strings.TrimSpace(string(out)), "\n", 2); len(lines) > 0 {
+		manifest.Synopsis = strings.TrimSpace(lines[0])
+	}
+	return manifest
+}
+
+// ExitError is returned when a plugin exits with a non-zero exit code. The
+// plugin has already reported any error itself.
+type ExitError struct {
+	name string
+	code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("plugin %q exited with code %d", e.name, e.code)
+}
+
+// Code returns the exit code of the plugin.
+func (e *ExitError) Code() int {
+	return e.code
+}
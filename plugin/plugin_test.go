@@ -0,0 +1,198 @@
+package plugin
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/spoke-d/clui/commands"
+	"github.com/spoke-d/task/group"
+)
+
+func TestFinder(t *testing.T) {
+	t.Parallel()
+
+	t.Run("find", func(t *testing.T) {
+		dir := tempDir(t)
+		defer os.RemoveAll(dir)
+		writeFile(t, dir, "cli-foo", "#!/bin/sh\n", 0755)
+		writeFile(t, dir, "cli-foo-bar", "#!/bin/sh\n", 0755)
+		writeFile(t, dir, "cli-dry_run", "#!/bin/sh\n", 0755)
+		writeFile(t, dir, "cli-foo.json", "{}", 0755)
+		writeFile(t, dir, "cli-noexec", "#!/bin/sh\n", 0644)
+		writeFile(t, dir, "other-foo", "#!/bin/sh\n", 0755)
+
+		plugins := NewFinder("cli", OptionPath(""), OptionDirs(dir)).Find()
+
+		var keys []string
+		for k := range plugins {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		if expected, actual := []string{"dry-run", "foo", "foo bar"}, keys; !reflect.DeepEqual(expected, actual) {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := filepath.Join(dir, "cli-foo"), plugins["foo"].Path(); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("first found", func(t *testing.T) {
+		first, second := tempDir(t), tempDir(t)
+		defer os.RemoveAll(first)
+		defer os.RemoveAll(second)
+		writeFile(t, first, "cli-foo", "#!/bin/sh\n", 0755)
+		writeFile(t, second, "cli-foo", "#!/bin/sh\n", 0755)
+
+		path := first + string(os.PathListSeparator) + "/does/not/exist"
+		plugins := NewFinder("cli", OptionPath(path), OptionDirs(second)).Find()
+		if expected, actual := filepath.Join(first, "cli-foo"), plugins["foo"].Path(); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+}
+
+func TestCommand(t *testing.T) {
+	t.Parallel()
+
+	t.Run("manifest", func(t *testing.T) {
+		dir := tempDir(t)
+		defer os.RemoveAll(dir)
+		writeFile(t, dir, "cli-foo", "#!/bin/sh\necho probed\n", 0755)
+		writeFile(t, dir, "cli-foo.json", `{"synopsis": "foo things", "help": "Foo all the things."}`, 0644)
+
+		cmd := NewFinder("cli", OptionPath(dir)).Find()["foo"]
+		if expected, actual := "foo things", cmd.Synopsis(); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := "Foo all the things.", cmd.Help(); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("synopsis probe", func(t *testing.T) {
+		dir := tempDir(t)
+		defer os.RemoveAll(dir)
+		writeFile(t, dir, "cli-foo", "#!/bin/sh\n[ \"$1\" = \"--synopsis\" ] && printf 'foo things\\nmore\\n'\n", 0755)
+
+		cmd := NewFinder("cli", OptionPath(dir)).Find()["foo"]
+		if expected, actual := "foo things", cmd.Synopsis(); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		path := filepath.Join(dir, "cli-foo")
+		if expected, actual := `External plugin command found at "`+path+`".`, cmd.Help(); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("synopsis probe once", func(t *testing.T) {
+		dir := tempDir(t)
+		defer os.RemoveAll(dir)
+		probes := filepath.Join(dir, "probes")
+		writeFile(t, dir, "cli-foo", "#!/bin/sh\necho probe >> "+probes+"\necho foo things\n", 0755)
+
+		cmd := NewFinder("cli", OptionPath(dir)).Find()["foo"]
+		cmd.Synopsis()
+		cmd.Help()
+		if expected, actual := "foo things", cmd.Synopsis(); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		b, err := ioutil.ReadFile(probes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected, actual := "probe\n", string(b); expected != actual {
+			t.Errorf("expected: %q, actual: %q", expected, actual)
+		}
+	})
+
+	t.Run("describe in parallel", func(t *testing.T) {
+		dir := tempDir(t)
+		defer os.RemoveAll(dir)
+		for _, name := range []string{"cli-a", "cli-b", "cli-c", "cli-d"} {
+			writeFile(t, dir, name, "#!/bin/sh\nsleep 1\necho "+name+"\n", 0755)
+		}
+
+		var cmds []*Command
+		for _, cmd := range NewFinder("cli", OptionPath(dir)).Find() {
+			cmds = append(cmds, cmd)
+		}
+
+		start := time.Now()
+		Describe(cmds...)
+		if elapsed := time.Since(start); elapsed > 3*time.Second {
+			t.Errorf("expected the plugins to be described in parallel, took %v", elapsed)
+		}
+		for _, cmd := range cmds {
+			if expected, actual := filepath.Base(cmd.Path()), cmd.Synopsis(); expected != actual {
+				t.Errorf("expected: %v, actual: %v", expected, actual)
+			}
+		}
+	})
+
+	t.Run("run", func(t *testing.T) {
+		dir := tempDir(t)
+		defer os.RemoveAll(dir)
+		writeFile(t, dir, "cli-foo", "#!/bin/sh\necho \"$@\"\n", 0755)
+
+		var stdout bytes.Buffer
+		cmd := NewFinder("cli", OptionPath(dir), OptionStdio(nil, &stdout, nil)).Find()["foo"]
+		if err := cmd.Init([]string{"bar", "--baz=1"}, commands.CommandContext{}); err != nil {
+			t.Fatal(err)
+		}
+
+		g := group.NewGroup()
+		cmd.Run(g)
+		err := g.Run()
+		if expected, actual := true, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v, err: %v", expected, actual, err)
+		}
+		if expected, actual := "bar --baz=1\n", stdout.String(); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("run exit code", func(t *testing.T) {
+		dir := tempDir(t)
+		defer os.RemoveAll(dir)
+		writeFile(t, dir, "cli-foo", "#!/bin/sh\nexit 3\n", 0755)
+
+		cmd := NewFinder("cli", OptionPath(dir)).Find()["foo"]
+		if err := cmd.Init([]string{}, commands.CommandContext{}); err != nil {
+			t.Fatal(err)
+		}
+
+		g := group.NewGroup()
+		cmd.Run(g)
+		err := g.Run()
+		exitErr, ok := err.(*ExitError)
+		if expected, actual := true, ok; expected != actual {
+			t.Fatalf("expected: %v, actual: %v, err: %v", expected, actual, err)
+		}
+		if expected, actual := 3, exitErr.Code(); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := `plugin "cli-foo" exited with code 3`, exitErr.Error(); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "plugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func writeFile(t *testing.T, dir, name, content string, mode os.FileMode) {
+	if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), mode); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,102 @@
+package clui
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spoke-d/clui/plugin"
+	"github.com/spoke-d/clui/ui"
+)
+
+func TestPlugins(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "plugins")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	probed := filepath.Join(dir, "probed")
+	script := "#!/bin/sh\n[ \"$1\" = \"--synopsis\" ] && touch " + probed + " && echo 'foo things' && exit 0\necho \"$@\"\nexit 3\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "cli-foo"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("run", func(t *testing.T) {
+		var stdout, buf bytes.Buffer
+		cli := New("cli", "1.0.0", "",
+			OptionUI(ui.NewBasicUI(nil, &buf, &buf)),
+			OptionAutoCompleter(nopAutoCompleter{}),
+			OptionStdio(nil, &stdout, nil),
+			OptionPlugins(plugin.OptionPath(dir)),
+		)
+
+		code, err := cli.Run([]string{"--debug", "foo", "bar", "--baz", "--debug", "-v", "--help", "--", "--format"})
+		if expected, actual := true, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v, err: %v", expected, actual, err)
+		}
+		if expected, actual := Errno(3), code; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := "bar --baz --debug -v --help -- --format\n", stdout.String(); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := "", buf.String(); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if _, err := os.Stat(probed); !os.IsNotExist(err) {
+			t.Errorf("expected the plugin to not be probed, err: %v", err)
+		}
+	})
+
+	t.Run("env and stdio", func(t *testing.T) {
+		var stdout, buf bytes.Buffer
+		cli := New("cli", "1.0.0", "",
+			OptionUI(ui.NewBasicUI(nil, &buf, &buf)),
+			OptionAutoCompleter(nopAutoCompleter{}),
+			OptionStdio(nil, &stdout, nil),
+			OptionEnv(func(key string) (string, bool) {
+				if key == "PATH" {
+					return dir, true
+				}
+				return "", false
+			}),
+			OptionPlugins(),
+		)
+
+		code, err := cli.Run([]string{"foo", "bar"})
+		if expected, actual := true, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v, err: %v", expected, actual, err)
+		}
+		if expected, actual := Errno(3), code; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := "bar\n", stdout.String(); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
+
+	t.Run("help", func(t *testing.T) {
+		var buf bytes.Buffer
+		cli := New("cli", "1.0.0", "",
+			OptionUI(ui.NewBasicUI(nil, &buf, &buf)),
+			OptionAutoCompleter(nopAutoCompleter{}),
+			OptionPlugins(plugin.OptionPath(dir)),
+		)
+
+		code, err := cli.Run([]string{"--help"})
+		if expected, actual := true, err == nil; expected != actual {
+			t.Errorf("expected: %v, actual: %v, err: %v", expected, actual, err)
+		}
+		if expected, actual := EOK, code; expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+		if expected, actual := true, bytes.Contains(buf.Bytes(), []byte("foo things")); expected != actual {
+			t.Errorf("expected: %v, actual: %v, output: %s", expected, actual, buf.String())
+		}
+	})
+}
@@ -20,5 +20,11 @@ Selection of commands that are nested under this one.
 
 // TemplateFlags describes a template for rendering flags in help.
 const TemplateFlags = `
-{{.Name}}	{{.Usage}} (defaults: "{{.Defaults}}")
+{{.Name}}{{if .Type}} <{{.Type}}>{{end}}	{{.Usage}}{{if .Choices}} (one of: {{.Choices}}){{end}}{{if .Required}} (required){{else}} (defaults: "{{.Defaults}}"){{end}}
+`
+
+// TemplateDeprecated describes a template for rendering a deprecated command
+// warning.
+const TemplateDeprecated = `
+Command {{ printf "%q" .Name }} is deprecated, use {{ printf "%q" .Replacement }} instead.
 `
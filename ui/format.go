@@ -0,0 +1,297 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// OutputFormat describes how the data passed to Output is rendered.
+type OutputFormat string
+
+const (
+	// OutputText renders the data using the template.
+	OutputText OutputFormat = "text"
+	// OutputJSON serialises the data as JSON.
+	OutputJSON OutputFormat = "json"
+	// OutputYAML serialises the data as YAML.
+	OutputYAML OutputFormat = "yaml"
+	// OutputTable renders the data as a table with a header row.
+	OutputTable OutputFormat = "table"
+)
+
+// OutputFormats returns all the output formats that are supported.
+func OutputFormats() []OutputFormat {
+	return []OutputFormat{OutputText, OutputJSON, OutputYAML, OutputTable}
+}
+
+// ParseOutputFormat returns the OutputFormat for a name. An empty name is the
+// text format.
+// Returns an error if the format isn't supported.
+func ParseOutputFormat(name string) (OutputFormat, error) {
+	if name == "" {
+		return OutputText, nil
+	}
+	for _, format := range OutputFormats() {
+		if string(format) == strings.ToLower(name) {
+			return format, nil
+		}
+	}
+	return OutputText, errors.Errorf("unsupported output format %q", name)
+}
+
+// Encode serialises the data in the format to the writer. The data is first
+// encoded as JSON, so that any json struct tags or json.Marshaler
+// implementations are used for all the formats.
+func (f OutputFormat) Encode(w io.Writer, data interface{}) error {
+	b, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if f == OutputJSON {
+		_, err := fmt.Fprintln(w, string(b))
+		return errors.WithStack(err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	value, err := decodeOrdered(dec)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	switch f {
+	case OutputYAML:
+		var b []byte
+		if b, err = yaml.Marshal(yamlValue(value)); err == nil {
+			_, err = w.Write(b)
+		}
+	case OutputTable:
+		tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+		encodeTable(tw, value)
+		err = tw.Flush()
+	default:
+		return errors.Errorf("unsupported output format %q", f)
+	}
+	return errors.WithStack(err)
+}
+
+// orderedMap is a JSON object that keeps the order of the keys, so that
+// structs are rendered in the order the fields are declared.
+type orderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func decodeOrdered(dec *json.Decoder) (interface{}, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token {
+	case json.Delim('{'):
+		m := &orderedMap{
+			values: make(map[string]interface{}),
+		}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			m.keys = append(m.keys, key.(string))
+			m.values[key.(string)] = value
+		}
+		_, err := dec.Token()
+		return m, err
+	case json.Delim('['):
+		list := make([]interface{}, 0)
+		for dec.More() {
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		_, err := dec.Token()
+		return list, err
+	}
+	return token, nil
+}
+
+// yamlValue converts the decoded value into a value that is marshalled in
+// the same order, with the numbers kept as numbers.
+func yamlValue(value interface{}) interface{} {
+	switch t := value.(type) {
+	case *orderedMap:
+		m := make(yaml.MapSlice, len(t.keys))
+		for i, key := range t.keys {
+			m[i] = yaml.MapItem{Key: key, Value: yamlValue(t.values[key])}
+		}
+		return m
+	case []interface{}:
+		list := make([]interface{}, len(t))
+		for i, v := range t {
+			list[i] = yamlValue(v)
+		}
+		return list
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i
+		}
+		if f, err := t.Float64(); err == nil {
+			return f
+		}
+		return t.String()
+	}
+	return value
+}
+
+// isPrint reports whether the string only contains printable runes, which
+// rules out newlines, tabs and other control characters.
+func isPrint(s string) bool {
+	for _, r := range s {
+		if !strconv.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func encodeTable(w io.Writer, value interface{}) {
+	// A struct or map with a single list is rendered as the list, so that
+	// template data such as struct{ Items []Item } is a table of items.
+	if m, ok := value.(*orderedMap); ok && len(m.keys) == 1 {
+		if list, ok := m.values[m.keys[0]].([]interface{}); ok {
+			value = list
+		}
+	}
+
+	switch t := value.(type) {
+	case []interface{}:
+		var headers []string
+		seen := make(map[string]struct{})
+		for _, row := range t {
+			m, ok := row.(*orderedMap)
+			if !ok {
+				continue
+			}
+			for _, key := range m.keys {
+				if _, ok := seen[key]; !ok {
+					seen[key] = struct{}{}
+					headers = append(headers, key)
+				}
+			}
+		}
+		if len(headers) == 0 {
+			fmt.Fprintln(w, "VALUE")
+			for _, row := range t {
+				fmt.Fprintln(w, tableCell(row, ""))
+			}
+			return
+		}
+		titles := upper(headers)
+		for i, title := range titles {
+			titles[i] = tableString(title, "")
+		}
+		writeRow(w, titles)
+		for _, row := range t {
+			m, ok := row.(*orderedMap)
+			if !ok {
+				m = &orderedMap{}
+			}
+			cells := make([]string, len(headers))
+			for i, header := range headers {
+				if v, ok := m.values[header]; ok {
+					cells[i] = tableCell(v, "")
+				}
+			}
+			writeRow(w, cells)
+		}
+	case *orderedMap:
+		writeRow(w, []string{"KEY", "VALUE"})
+		for _, key := range t.keys {
+			writeRow(w, []string{tableString(key, ""), tableCell(t.values[key], "")})
+		}
+	default:
+		fmt.Fprintln(w, tableCell(t, ""))
+	}
+}
+
+func writeRow(w io.Writer, cells []string) {
+	// Trailing empty cells are dropped, so rows don't end with padding.
+	for len(cells) > 0 && cells[len(cells)-1] == "" {
+		cells = cells[:len(cells)-1]
+	}
+	fmt.Fprintln(w, strings.Join(cells, "\t"))
+}
+
+// tableCell renders the value as a single cell. Strings are quoted if they
+// contain any of the separators used to join the nested value they're in.
+func tableCell(value interface{}, separators string) string {
+	switch t := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return tableString(t, separators)
+	case *orderedMap:
+		parts := make([]string, len(t.keys))
+		for i, key := range t.keys {
+			parts[i] = fmt.Sprintf("%s=%s", tableString(key, ",="), tableCell(t.values[key], ",="))
+		}
+		return strings.Join(parts, ",")
+	case []interface{}:
+		parts := make([]string, len(t))
+		for i, v := range t {
+			parts[i] = tableCell(v, ",")
+		}
+		return strings.Join(parts, ",")
+	default:
+		return fmt.Sprint(t)
+	}
+}
+
+// tableString quotes the string if it contains characters that would break
+// the row, or any of the separators.
+func tableString(s, separators string) string {
+	if !isPrint(s) || strings.ContainsAny(s, separators) {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+func upper(values []string) []string {
+	res := make([]string, len(values))
+	for i, v := range values {
+		res[i] = strings.ToUpper(v)
+	}
+	return res
+}
+
+func isScalar(value interface{}) bool {
+	switch value.(type) {
+	case *orderedMap, []interface{}:
+		return false
+	}
+	return true
+}
+
+func isEmpty(value interface{}) bool {
+	switch t := value.(type) {
+	case *orderedMap:
+		return len(t.keys) == 0
+	case []interface{}:
+		return len(t) == 0
+	}
+	return false
+}
@@ -0,0 +1,236 @@
+package ui
+
+import (
+	"bytes"
+	"testing"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+type formatItem struct {
+	Key   string      `json:"key"`
+	Value interface{} `json:"value"`
+}
+
+func TestParseOutputFormat(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"", "text", "json", "YAML", "table"} {
+		if _, err := ParseOutputFormat(name); err != nil {
+			t.Errorf("expected: %v, actual: %v", nil, err)
+		}
+	}
+
+	_, err := ParseOutputFormat("xml")
+	if expected, actual := `unsupported output format "xml"`, err.Error(); expected != actual {
+		t.Errorf("expected: %v, actual: %v", expected, actual)
+	}
+}
+
+func TestOutputFormatEncode(t *testing.T) {
+	t.Parallel()
+
+	data := struct {
+		Items []formatItem `json:"items"`
+	}{
+		Items: []formatItem{
+			{Key: "name", Value: "fred"},
+			{Key: "age", Value: 42},
+			{Key: "tags", Value: []string{"a", "b"}},
+			{Key: "empty", Value: ""},
+		},
+	}
+
+	testCases := []struct {
+		format   OutputFormat
+		expected string
+	}{
+		{
+			format: OutputJSON,
+			expected: `{
+  "items": [
+    {
+      "key": "name",
+      "value": "fred"
+    },
+    {
+      "key": "age",
+      "value": 42
+    },
+    {
+      "key": "tags",
+      "value": [
+        "a",
+        "b"
+      ]
+    },
+    {
+      "key": "empty",
+      "value": ""
+    }
+  ]
+}
+`,
+		},
+		{
+			format: OutputYAML,
+			expected: `items:
+- key: name
+  value: fred
+- key: age
+  value: 42
+- key: tags
+  value:
+  - a
+  - b
+- key: empty
+  value: ""
+`,
+		},
+		{
+			format: OutputTable,
+			expected: `KEY   VALUE
+name  fred
+age   42
+tags  a,b
+empty
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tc.format.Encode(&buf, data); err != nil {
+				t.Fatal(err)
+			}
+			if expected, actual := tc.expected, buf.String(); expected != actual {
+				t.Errorf("expected: %q, actual: %q", expected, actual)
+			}
+		})
+	}
+}
+
+func TestOutputFormatEncodeYAMLRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	values := []string{
+		"fred",
+		"",
+		"true",
+		"yes",
+		"off",
+		"y",
+		"~",
+		"null",
+		"1.5",
+		"1e3",
+		"0x1F",
+		".inf",
+		"- item",
+		"-1x",
+		"key: value",
+		"key:",
+		":key",
+		"# comment",
+		"value # comment",
+		"issue#1",
+		"http://localhost:8080",
+		"line\nbreak",
+		"trailing\n",
+		"carriage\rreturn",
+		"tab\tstop",
+		` leading space`,
+		`say "hi"`,
+		"{flow}",
+		"[flow]",
+		"*anchor",
+		"!tag",
+	}
+	for _, value := range values {
+		var buf bytes.Buffer
+		if err := OutputYAML.Encode(&buf, map[string]string{"value": value}); err != nil {
+			t.Fatal(err)
+		}
+
+		var decoded map[string]interface{}
+		if err := yaml.Unmarshal(buf.Bytes(), &decoded); err != nil {
+			t.Fatalf("%q: %v", buf.String(), err)
+		}
+		if expected, actual := interface{}(value), decoded["value"]; expected != actual {
+			t.Errorf("expected: %q, actual: %q, yaml: %q", expected, actual, buf.String())
+		}
+	}
+}
+
+func TestOutputFormatEncodeQuoting(t *testing.T) {
+	t.Parallel()
+
+	data := struct {
+		Items []formatItem `json:"items"`
+	}{
+		Items: []formatItem{
+			{Key: "url: path", Value: "http://localhost:8080"},
+			{Key: "#hash", Value: "value # comment"},
+			{Key: "-dash", Value: "-1"},
+			{Key: "lines", Value: "first\nsecond"},
+			{Key: "tabs", Value: "a\tb"},
+			{Key: "list", Value: []string{"a,b", "c"}},
+			{Key: "map", Value: map[string]string{"k=v": "x,y"}},
+		},
+	}
+
+	testCases := []struct {
+		format   OutputFormat
+		expected string
+	}{
+		{
+			format: OutputYAML,
+			expected: `items:
+- key: 'url: path'
+  value: http://localhost:8080
+- key: '#hash'
+  value: 'value # comment'
+- key: -dash
+  value: "-1"
+- key: lines
+  value: |-
+    first
+    second
+- key: tabs
+  value: "a\tb"
+- key: list
+  value:
+  - a,b
+  - c
+- key: map
+  value:
+    k=v: x,y
+`,
+		},
+		{
+			format: OutputTable,
+			expected: `KEY        VALUE
+url: path  http://localhost:8080
+#hash      value # comment
+-dash      -1
+lines      "first\nsecond"
+tabs       "a\tb"
+list       "a,b",c
+map        "k=v"="x,y"
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tc.format.Encode(&buf, data); err != nil {
+				t.Fatal(err)
+			}
+			if expected, actual := tc.expected, buf.String(); expected != actual {
+				t.Errorf("expected: %q, actual: %q", expected, actual)
+			}
+		})
+	}
+}
@@ -20,6 +20,7 @@ type BasicUI struct {
 	stdin  io.Reader
 	stdout io.Writer
 	stderr io.Writer
+	format OutputFormat
 }
 
 // NewBasicUI creates a new BasicUI with dependencies.
@@ -28,9 +29,20 @@ func NewBasicUI(stdin io.Reader, stdout, stderr io.Writer) *BasicUI {
 		stdin:  stdin,
 		stdout: stdout,
 		stderr: stderr,
+		format: OutputText,
 	}
 }
 
+// SetOutputFormat sets the format that Output renders the data with.
+func (u *BasicUI) SetOutputFormat(format OutputFormat) {
+	u.format = format
+}
+
+// OutputFormat returns the format that Output renders the data with.
+func (u *BasicUI) OutputFormat() OutputFormat {
+	return u.format
+}
+
 // Ask asks the user for input using the given query. The response is
 // returned as the given string, or an error.
 func (u *BasicUI) Ask(query string) (string, error) {
@@ -56,8 +68,13 @@ func (u *BasicUI) Info(message string) {
 	fmt.Fprintln(u.stdout, message)
 }
 
-// Output is called for normal standard output.
+// Output is called for normal standard output. If the output format isn't
+// text, the data is serialised directly and the template isn't used.
 func (u *BasicUI) Output(template *Template, data interface{}) error {
+	if u.format != OutputText && u.format != "" {
+		return u.format.Encode(u.stdout, data)
+	}
+
 	result, err := template.Render(data)
 	if err != nil {
 		return errors.WithStack(err)
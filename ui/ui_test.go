@@ -51,4 +51,19 @@ func TestBasicUI(t *testing.T) {
 			t.Errorf("expected: %v, actual: %v", expected, actual)
 		}
 	})
+
+	t.Run("output format", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		ui := NewBasicUI(nil, &buf, nil)
+		ui.SetOutputFormat(OutputJSON)
+		ui.Output(NewTemplate("{{.Name}}"), struct {
+			Name string `json:"name"`
+		}{
+			Name: "Fred",
+		})
+		if expected, actual := "{\n  \"name\": \"Fred\"\n}\n", buf.String(); expected != actual {
+			t.Errorf("expected: %v, actual: %v", expected, actual)
+		}
+	})
 }